@@ -0,0 +1,150 @@
+// Package badge 生成 shields 风格的覆盖率徽章(SVG)，不依赖网络
+package badge
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// DefaultThresholds 与 DefaultColors 是默认的颜色分档：低于 50% 为红色，依次类推，不低于 90% 为亮绿色
+var (
+	DefaultThresholds = []float64{50, 75, 90}
+	DefaultColors     = []string{"red", "orange", "yellow", "brightgreen"}
+)
+
+// namedColors 是 shields 的配色
+var namedColors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+}
+
+// Badge 是一个徽章
+type Badge struct {
+	// Label 是左侧的文字
+	Label string
+	// Value 是右侧的文字
+	Value string
+	// Color 是右侧的背景色，可以是 shields 的颜色名或 #rgb、#rrggbb
+	Color string
+}
+
+// Scale 是覆盖率到颜色的分档
+type Scale struct {
+	// Thresholds 是升序的分档阈值(百分比)
+	Thresholds []float64
+	// Colors 是各档的颜色，比 Thresholds 多一个：覆盖率低于 Thresholds[i] 时使用 Colors[i]
+	Colors []string
+}
+
+// Validate 检查分档是否有效
+func (s *Scale) Validate() error {
+	if len(s.Colors) != len(s.Thresholds)+1 {
+		return fmt.Errorf("expected %d colors for %d thresholds, got %d", len(s.Thresholds)+1, len(s.Thresholds), len(s.Colors))
+	}
+	for i := 1; i < len(s.Thresholds); i++ {
+		if s.Thresholds[i] < s.Thresholds[i-1] {
+			return fmt.Errorf("thresholds must be in ascending order. %v", s.Thresholds)
+		}
+	}
+	for _, c := range s.Colors {
+		if _, err := hexColor(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Color 返回覆盖率所在分档的颜色
+func (s *Scale) Color(percent float64) string {
+	for i, t := range s.Thresholds {
+		if percent < t {
+			return s.Colors[i]
+		}
+	}
+	return s.Colors[len(s.Colors)-1]
+}
+
+// hexColor 将颜色名转换为十六进制颜色
+func hexColor(c string) (string, error) {
+	if hex, ok := namedColors[c]; ok {
+		return hex, nil
+	}
+	if strings.HasPrefix(c, "#") && (len(c) == 4 || len(c) == 7) && strings.Trim(c[1:], "0123456789abcdefABCDEF") == "" {
+		return c, nil
+	}
+	return "", fmt.Errorf("unsupported color. [%s]", c)
+}
+
+// textWidth 估算 11px Verdana 文字的宽度
+func textWidth(s string) int {
+	var w float64
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("ijl.,:;!|'", r):
+			w += 3.5
+		case strings.ContainsRune("fIrt()[] ", r):
+			w += 4.5
+		case strings.ContainsRune("mwMW%", r):
+			w += 10.5
+		case r >= 'A' && r <= 'Z':
+			w += 7.5
+		default:
+			w += 7
+		}
+	}
+	return int(w + 0.5)
+}
+
+// padding 是文字两侧的留白
+const padding = 10
+
+var badgeTemplate = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{html .Label}}: {{html .Value}}">
+  <title>{{html .Label}}: {{html .Value}}</title>
+  <linearGradient id="s" x2="0" y2="100%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="{{.Width}}" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="{{.LabelWidth}}" height="20" fill="#555"/>
+    <rect x="{{.LabelWidth}}" width="{{.ValueWidth}}" height="20" fill="{{.Color}}"/>
+    <rect width="{{.Width}}" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="{{.LabelX}}" y="15" fill="#010101" fill-opacity=".3">{{html .Label}}</text>
+    <text x="{{.LabelX}}" y="14">{{html .Label}}</text>
+    <text x="{{.ValueX}}" y="15" fill="#010101" fill-opacity=".3">{{html .Value}}</text>
+    <text x="{{.ValueX}}" y="14">{{html .Value}}</text>
+  </g>
+</svg>
+`))
+
+// Write 将徽章以 SVG 格式输出到 w
+func (b *Badge) Write(w io.Writer) error {
+	color, err := hexColor(b.Color)
+	if err != nil {
+		return err
+	}
+	labelWidth := textWidth(b.Label) + padding
+	valueWidth := textWidth(b.Value) + padding
+	return badgeTemplate.Execute(w, map[string]interface{}{
+		"Label":      b.Label,
+		"Value":      b.Value,
+		"Color":      color,
+		"Width":      labelWidth + valueWidth,
+		"LabelWidth": labelWidth,
+		"ValueWidth": valueWidth,
+		"LabelX":     float64(labelWidth) / 2,
+		"ValueX":     float64(labelWidth) + float64(valueWidth)/2,
+	})
+}
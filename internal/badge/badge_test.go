@@ -0,0 +1,43 @@
+package badge
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestScale(t *testing.T) {
+	s := &Scale{Thresholds: DefaultThresholds, Colors: DefaultColors}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for percent, want := range map[float64]string{0: "red", 49.99: "red", 50: "orange", 89.9: "yellow", 90: "brightgreen", 100: "brightgreen"} {
+		if got := s.Color(percent); got != want {
+			t.Errorf("Color(%v) = %s, want %s", percent, got, want)
+		}
+	}
+
+	for _, bad := range []*Scale{
+		{Thresholds: []float64{50}, Colors: []string{"red"}},
+		{Thresholds: []float64{80, 50}, Colors: []string{"red", "yellow", "green"}},
+		{Thresholds: []float64{50}, Colors: []string{"red", "#12345g"}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", bad)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	b := &Badge{Label: "a&b coverage", Value: "85.0%", Color: "#abc"}
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	for _, want := range []string{`aria-label="a&amp;b coverage: 85.0%"`, `fill="#abc"`, `>85.0%</text>`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("badge does not contain %s:\n%s", want, svg)
+		}
+	}
+}
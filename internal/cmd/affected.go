@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lamber92/go-cover/internal/diff"
+	"github.com/lamber92/go-cover/internal/impact"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var affectedCmd = &cobra.Command{
+	Use:   "affected-tests",
+	Short: "affected-tests ${coverage.json}",
+	Long:  "affected-tests ${coverage.json}",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAffected(args)
+	},
+}
+
+func init() {
+	affectedCmd.Flags().StringVarP(&targetBranch, "base", "t", diff.DefaultTargetBranch, "The branch that was compared to find the difference")
+	affectedCmd.Flags().StringVarP(&currentBranch, "current-branch", "c", "", "The current branch under test")
+	affectedCmd.Flags().StringVarP(&hashIdsRangeParam, "hash-ids-range", "i", "", "The range of hash-ids that need to be reserved. format: 'start-hash-id,end-hash-id'")
+	affectedCmd.Flags().StringVarP(&difference, "diff", "d", "", "The file-path witch record code difference information")
+
+	rootCmd.AddCommand(affectedCmd)
+}
+
+// runAffected 按差异选出执行过新代码行的测试，每个包输出一行: 包导入路径 与 go test -run 正则
+func runAffected(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected at least one coverage json")
+	}
+	packages, err := utils.ReadPackages(args)
+	if err != nil {
+		return fmt.Errorf("failed to load coverage json. err: %w", err)
+	}
+	if !impact.HasTests(packages) {
+		return fmt.Errorf("no per-test coverage in the json, generate it with 'test --per-test -o json-only'")
+	}
+
+	diffPackages, _, err := trimDiff(packages)
+	if err != nil {
+		return err
+	}
+	result := impact.Select(diffPackages)
+	if result.Untested > 0 {
+		log.Printf("%d new lines are not executed by any test.\n", result.Untested)
+	}
+	for _, p := range result.Packages {
+		fmt.Fprintf(os.Stdout, "%s\t%s\n", p.Package, p.RunPattern())
+	}
+	return nil
+}
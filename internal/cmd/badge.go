@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamber92/go-cover/internal/badge"
+	"github.com/lamber92/go-cover/internal/diff"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	badgeScopeTotal = "total" // 全量覆盖率
+	badgeScopeDiff  = "diff"  // 增量覆盖率
+)
+
+var (
+	badgeScope      string
+	badgeLabel      string
+	badgeMetric     string
+	badgeOutput     string
+	badgePackageDir string
+	badgeThresholds []float64
+	badgeColors     []string
+)
+
+var badgeCmd = &cobra.Command{
+	Use:   "badge",
+	Short: "badge ${coverage.json}",
+	Long:  "badge ${coverage.json}",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBadge(args)
+	},
+}
+
+func init() {
+	badgeCmd.Flags().StringVar(&badgeScope, "scope", badgeScopeTotal, "Options: 'total' or 'diff'; Default: 'total'")
+	badgeCmd.Flags().StringVarP(&badgeLabel, "label", "l", "", "The label of the badge. Default: 'coverage' or 'diff coverage'")
+	badgeCmd.Flags().StringVar(&badgeMetric, "metric", string(metadata.MetricStatement), "The coverage metric. Options: 'statement', 'line', 'block' or 'branch'")
+	badgeCmd.Flags().StringVarP(&badgeOutput, "output", "o", "coverage.svg", "The file-path of the badge")
+	badgeCmd.Flags().StringVar(&badgePackageDir, "package-dir", "", "Also write a badge for every package into the directory")
+	badgeCmd.Flags().Float64SliceVar(&badgeThresholds, "thresholds", badge.DefaultThresholds, "The ascending coverage percentages where the colour changes")
+	badgeCmd.Flags().StringSliceVar(&badgeColors, "colors", badge.DefaultColors, "The colours below each threshold and above the last one, as shields names or hex '#rrggbb'")
+	badgeCmd.Flags().StringVarP(&difference, "diff", "d", "", "The file-path witch record code difference information")
+	badgeCmd.Flags().StringVarP(&currentBranch, "current-branch", "c", "", "The current branch under test")
+	badgeCmd.Flags().StringVarP(&targetBranch, "target-branch", "t", diff.DefaultTargetBranch, "The branch that was compared to find the difference")
+	badgeCmd.Flags().StringVarP(&hashIdsRangeParam, "hash-ids-range", "i", "", "The range of hash-ids that need to be reserved. format: 'start-hash-id,end-hash-id'")
+
+	rootCmd.AddCommand(badgeCmd)
+}
+
+func runBadge(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected at least one coverage json")
+	}
+	metric, err := metadata.ParseMetric(badgeMetric)
+	if err != nil {
+		return err
+	}
+	scale := &badge.Scale{Thresholds: badgeThresholds, Colors: badgeColors}
+	if err = scale.Validate(); err != nil {
+		return err
+	}
+	packages, err := utils.ReadPackages(args)
+	if err != nil {
+		return fmt.Errorf("failed to load coverage json. err: %w", err)
+	}
+
+	label := badgeLabel
+	switch badgeScope {
+	case badgeScopeTotal:
+		if label == "" {
+			label = "coverage"
+		}
+	case badgeScopeDiff:
+		if label == "" {
+			label = "diff coverage"
+		}
+		if packages, _, err = trimDiff(packages); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported scope. [%s]", badgeScope)
+	}
+
+	var total metadata.Coverage
+	for _, p := range packages {
+		total.Add(p.Coverage(metric))
+	}
+	if err = writeBadge(badgeOutput, label, total, scale); err != nil {
+		return err
+	}
+	if len(badgePackageDir) > 0 {
+		for _, p := range packages {
+			name := strings.Replace(p.Name, "/", "_", -1) + ".svg"
+			if err = writeBadge(filepath.Join(badgePackageDir, name), path.Base(p.Name), p.Coverage(metric), scale); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeBadge 按覆盖率生成徽章并写入文件，没有可统计的对象时显示 n/a
+func writeBadge(file, label string, c metadata.Coverage, scale *badge.Scale) error {
+	b := &badge.Badge{Label: label, Value: "n/a", Color: "lightgrey"}
+	if c.Total > 0 {
+		b.Value = fmt.Sprintf("%.1f%%", c.Percent())
+		b.Color = scale.Color(c.Percent())
+	}
+	f, err := utils.CreateFile(filepath.Dir(file), filepath.Base(file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = b.Write(f); err != nil {
+		return fmt.Errorf("failed to write badge %s. err: %w", file, err)
+	}
+	log.Printf("Write badge %s success.\n", file)
+	return nil
+}
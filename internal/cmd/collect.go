@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/collect"
+	"github.com/lamber92/go-cover/internal/convert"
+	"github.com/lamber92/go-cover/internal/diff"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	collectAPIGoc     = "goc"     // goc 服务端
+	collectAPIProfile = "profile" // 以 GET 直接返回 profile 的地址
+	collectAPIRuntime = "runtime" // pkg/livecover 提供的接口
+)
+
+var (
+	collectAPI      string
+	collectReset    bool
+	collectResetURL string
+	collectProfile  string
+	collectMerge    []string
+	collectInterval time.Duration
+	collectSnapDir  string
+)
+
+var collectCmd = &cobra.Command{
+	Use:   "collect",
+	Short: "collect ${url}",
+	Long: `collect ${url}
+	fetch the coverage profile of running programs from a goc server, pkg/livecover or an HTTP endpoint, then convert it and build the reports`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCollect(args)
+	},
+}
+
+func init() {
+	collectCmd.Flags().StringVar(&collectAPI, "api", collectAPIGoc, "Options: 'goc' (the url is a goc server), 'profile' (the url returns a coverage profile) or 'runtime' (the url is served by pkg/livecover); Default: 'goc'")
+	collectCmd.Flags().BoolVar(&collectReset, "reset", false, "Reset the coverage counters after the profile is fetched")
+	collectCmd.Flags().StringVar(&collectResetURL, "reset-url", "", "The url that resets the coverage counters by POST, required by --reset with --api profile")
+	collectCmd.Flags().StringVar(&collectProfile, "profile", "", "Keep the fetched coverage profile at the file-path. Default: a temporary file that is removed")
+	collectCmd.Flags().DurationVar(&collectInterval, "interval", 0, "Collect periodically at the interval and save timestamped json snapshots into --snapshot-dir until interrupted. Default: collect once")
+	collectCmd.Flags().StringVar(&collectSnapDir, "snapshot-dir", "", "The directory of the snapshots saved by --interval")
+	collectCmd.Flags().StringSliceVar(&collectMerge, "merge", nil, "The go-cover json files that are merged with the fetched coverage")
+	collectCmd.Flags().StringVarP(&outputMode, "output-mode", "o", outputModeAll, "Options: 'full-only', 'diff-only', 'json-only', 'text-only' or 'markdown-only'; Default: 'all'")
+	collectCmd.Flags().StringVarP(&css, "css-format", "f", "", "The file-path witch record customized report themes within CSS-format")
+	collectCmd.Flags().StringVarP(&difference, "diff", "d", "", "The file-path witch record code difference information")
+	collectCmd.Flags().StringVarP(&currentBranch, "current-branch", "c", "", "The current branch under test")
+	collectCmd.Flags().StringVarP(&targetBranch, "target-branch", "t", diff.DefaultTargetBranch, "The branch that was compared to find the difference")
+	collectCmd.Flags().StringVarP(&hashIdsRangeParam, "hash-ids-range", "i", "", "The range of hash-ids that need to be reserved. format: 'start-hash-id,end-hash-id'")
+	collectCmd.Flags().BoolVar(&embedSrc, "embed-source", false, "Embed the compressed source of every covered file into the json, so that reports can be rendered offline")
+	collectCmd.Flags().BoolVar(&gitSource, "git-source", false, "Read the original source from git at the recorded commit when a source file has changed since conversion")
+	collectCmd.Flags().BoolVarP(&keepGoing, "keep-going", "k", false, "Skip the source files that are missing or cannot be parsed, and list them in the report")
+	collectCmd.Flags().StringVar(&sortMethod, "sort", string(types.SortByCoverage), "The order of functions in reports. Options: 'coverage', 'uncovered', 'crap', 'complexity' or 'name'")
+	collectCmd.Flags().StringVar(&historyDir, "history", "", "The directory of the coverage history store; a snapshot is appended to it after the reports are built")
+	collectCmd.Flags().IntVar(&workers, "workers", 0, "The number of files converted concurrently. Default: the number of CPUs")
+	addGateFlags(collectCmd)
+	addExportFlags(collectCmd)
+
+	rootCmd.AddCommand(collectCmd)
+}
+
+func runCollect(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected the url of the coverage source")
+	}
+	source, err := collectSource(args[0])
+	if err != nil {
+		return err
+	}
+	if collectInterval > 0 {
+		return collectSnapshots(source)
+	}
+
+	profile := collectProfile
+	if profile == "" {
+		file, err := ioutil.TempFile("", "go-cover-*.out")
+		if err != nil {
+			return err
+		}
+		file.Close()
+		profile = file.Name()
+		defer os.Remove(profile)
+	}
+	if err = collect.FetchFile(source, profile); err != nil {
+		return err
+	}
+	// 取到 profile 后再清零，避免丢失两次采集之间的计数
+	if collectReset {
+		if err = source.Reset(); err != nil {
+			return err
+		}
+		log.Println("Reset coverage counters success.")
+	}
+
+	packages, skipped, err := convert.Do(profile, &convert.Param{EmbedSource: embedSrc, KeepGoing: keepGoing, Workers: workers})
+	if err != nil {
+		return err
+	}
+	for _, v := range skipped {
+		log.Printf("Skip file[%s]. reason: %s\n", v.File, v.Reason)
+	}
+	if len(collectMerge) > 0 {
+		merged, err := utils.ReadPackages(collectMerge)
+		if err != nil {
+			return fmt.Errorf("failed to load coverage json. err: %w", err)
+		}
+		for _, p := range merged {
+			if err = packages.AppendPackage(p); err != nil {
+				return err
+			}
+		}
+	}
+	return writeOutputs(packages, skipped)
+}
+
+// collectSource 按 --api 选项创建覆盖率来源
+func collectSource(url string) (collect.Source, error) {
+	switch collectAPI {
+	case collectAPIGoc:
+		return collect.NewGocSource(url), nil
+	case collectAPIProfile:
+		if collectReset && len(collectResetURL) == 0 {
+			return nil, fmt.Errorf("--reset-url is required to reset the counters with --api profile")
+		}
+		return &collect.HTTPSource{ProfileURL: url, ResetURL: collectResetURL}, nil
+	case collectAPIRuntime:
+		return &collect.RuntimeSource{URL: url}, nil
+	default:
+		return nil, fmt.Errorf("unsupported api. [%s]", collectAPI)
+	}
+}
+
+// collectSnapshots 定期采集覆盖率，每次转换后以采集时刻命名保存到快照目录，直到被中断。
+// 两个快照可用 report --subtract 相减，得到这段时间内执行过的代码。
+func collectSnapshots(source collect.Source) error {
+	if len(collectSnapDir) == 0 {
+		return fmt.Errorf("--snapshot-dir is required by --interval")
+	}
+	if collectReset {
+		return fmt.Errorf("--reset cannot be used with --interval, subtract the snapshots instead")
+	}
+	if err := os.MkdirAll(collectSnapDir, 0755); err != nil {
+		return err
+	}
+	stop := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt)
+		<-signals
+		close(stop)
+	}()
+
+	return collect.Poll(source, collectInterval, stop, func(profile string, at time.Time) error {
+		packages, skipped, err := convert.Do(profile, &convert.Param{EmbedSource: embedSrc, KeepGoing: keepGoing, Workers: workers})
+		if err != nil {
+			return err
+		}
+		for _, v := range skipped {
+			log.Printf("Skip file[%s]. reason: %s\n", v.File, v.Reason)
+		}
+		path := collect.SnapshotFile(collectSnapDir, at)
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err = utils.MarshalJson(file, packages); err != nil {
+			return fmt.Errorf("failed to save snapshot. err: %w", err)
+		}
+		log.Printf("Save snapshot[%s] success.\n", path)
+		return nil
+	})
+}
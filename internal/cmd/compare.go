@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lamber92/go-cover/internal/compare"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	compareOutputText     = "text"     // 输出文本到stdout
+	compareOutputMarkdown = "markdown" // 输出Markdown到stdout
+	compareOutputHTML     = "html"     // 输出 compare.html
+)
+
+var (
+	compareOutput string
+	compareMetric string
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "compare ${old-coverage.json} ${new-coverage.json}",
+	Long:  "compare ${old-coverage.json} ${new-coverage.json}",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCompare(args)
+	},
+}
+
+func init() {
+	compareCmd.Flags().StringVarP(&compareOutput, "output-mode", "o", compareOutputText, "Options: 'text', 'markdown' or 'html'; Default: 'text'")
+	compareCmd.Flags().StringVar(&compareMetric, "metric", string(metadata.MetricStatement), "The coverage metric to compare. Options: 'statement', 'line', 'block' or 'branch'")
+
+	rootCmd.AddCommand(compareCmd)
+}
+
+func runCompare(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected the old and the new coverage json")
+	}
+	metric, err := metadata.ParseMetric(compareMetric)
+	if err != nil {
+		return err
+	}
+	old, err := utils.ReadPackages(args[:1])
+	if err != nil {
+		return fmt.Errorf("failed to load old coverage json. err: %w", err)
+	}
+	new, err := utils.ReadPackages(args[1:])
+	if err != nil {
+		return fmt.Errorf("failed to load new coverage json. err: %w", err)
+	}
+	result := compare.Do(old, new, metric)
+
+	switch compareOutput {
+	case compareOutputText:
+		return report.WriteCompareText(os.Stdout, result)
+	case compareOutputMarkdown:
+		return report.WriteCompareMarkdown(os.Stdout, result)
+	case compareOutputHTML:
+		file, err := utils.CreateFile(".", utils.CompareHTML)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err = report.WriteCompareHTML(file, result); err != nil {
+			return fmt.Errorf("failed to generate compare report. err: %w", err)
+		}
+		log.Println("Generate compare report success.")
+		return nil
+	default:
+		return fmt.Errorf("unsupported output mode. [%s]", compareOutput)
+	}
+}
@@ -1,133 +1,261 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/jinzhu/copier"
 	"github.com/lamber92/go-cover/internal/convert"
 	"github.com/lamber92/go-cover/internal/diff"
+	"github.com/lamber92/go-cover/internal/errs"
+	"github.com/lamber92/go-cover/internal/gate"
 	"github.com/lamber92/go-cover/internal/metadata"
 	"github.com/lamber92/go-cover/internal/report"
+	"github.com/lamber92/go-cover/internal/report/types"
 	"github.com/lamber92/go-cover/internal/trim"
 	"github.com/lamber92/go-cover/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 const (
-	outputModeAll      = "all"       // 都要
-	outputModeOnlyFull = "full-only" // 只要全量报告
-	outputModeOnlyDiff = "diff-only" // 只要增量报告
-	outputModeOnlyJson = "json-only" // 只要全量的json(只输出到stdout)
+	outputModeAll      = "all"           // 都要
+	outputModeOnlyFull = "full-only"     // 只要全量报告
+	outputModeOnlyDiff = "diff-only"     // 只要增量报告
+	outputModeOnlyJson = "json-only"     // 只要全量的json(只输出到stdout)
+	outputModeOnlyText = "text-only"     // 只要全量的文本报告(只输出到stdout)
+	outputModeOnlyMD   = "markdown-only" // 只要全量的Markdown报告(只输出到stdout)
 )
 
 var (
 	outputMode string
 	css        string
 	difference string
+	gitSource  bool
+	embedSrc   bool
+	keepGoing  bool
+	workers    int
+	sortMethod string
+	historyDir string
 )
 
 var covertCmd = &cobra.Command{
 	Use:   "convert",
 	Short: "convert ${coverage.profile}",
 	Long:  "convert ${coverage.profile}",
-	Run: func(cmd *cobra.Command, args []string) {
-		runConvert(args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConvert(args)
 	},
 }
 
 func init() {
-	covertCmd.Flags().StringVarP(&outputMode, "output-mode", "o", outputModeAll, "Options: 'full-only' or 'diff-only'; Default: 'all'")
+	covertCmd.Flags().StringVarP(&outputMode, "output-mode", "o", outputModeAll, "Options: 'full-only', 'diff-only', 'json-only', 'text-only' or 'markdown-only'; Default: 'all'")
 	covertCmd.Flags().StringVarP(&css, "css-format", "f", "", "The file-path witch record customized report themes within CSS-format")
 	covertCmd.Flags().StringVarP(&difference, "diff", "d", "", "The file-path witch record code difference information")
 	covertCmd.Flags().StringVarP(&currentBranch, "current-branch", "c", "", "The current branch under test")
-	covertCmd.Flags().StringVarP(&targetBranch, "target-branch", "t", defaultTargetBranch, "The branch that was compared to find the difference")
+	covertCmd.Flags().StringVarP(&targetBranch, "target-branch", "t", diff.DefaultTargetBranch, "The branch that was compared to find the difference")
 	covertCmd.Flags().StringVarP(&hashIdsRangeParam, "hash-ids-range", "i", "", "The range of hash-ids that need to be reserved. format: 'start-hash-id,end-hash-id'")
+	covertCmd.Flags().BoolVar(&embedSrc, "embed-source", false, "Embed the compressed source of every covered file into the json, so that reports can be rendered offline")
+	covertCmd.Flags().BoolVar(&gitSource, "git-source", false, "Read the original source from git at the recorded commit when a source file has changed since conversion")
+	covertCmd.Flags().BoolVarP(&keepGoing, "keep-going", "k", false, "Skip the source files that are missing or cannot be parsed, and list them in the report")
+	covertCmd.Flags().StringVar(&sortMethod, "sort", string(types.SortByCoverage), "The order of functions in reports. Options: 'coverage', 'uncovered', 'crap', 'complexity' or 'name'")
+	covertCmd.Flags().StringVar(&historyDir, "history", "", "The directory of the coverage history store; a snapshot is appended to it after the reports are built")
+	covertCmd.Flags().IntVar(&workers, "workers", 0, "The number of files converted concurrently. Default: the number of CPUs")
+	addGateFlags(covertCmd)
+	addExportFlags(covertCmd)
 
 	rootCmd.AddCommand(covertCmd)
 }
 
-func runConvert(args []string) {
+func runConvert(args []string) error {
 	if len(args) == 0 {
-		log.Fatalln("Expected at least one coverage profile.")
-		return
+		return fmt.Errorf("expected at least one coverage profile")
 	}
 
-	packages, err := convert.Do(args[0])
+	packages, skipped, err := convert.Do(args[0], &convert.Param{EmbedSource: embedSrc, KeepGoing: keepGoing, Workers: workers})
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
+	for _, v := range skipped {
+		log.Printf("Skip file[%s]. reason: %s\n", v.File, v.Reason)
+	}
+
+	return writeOutputs(packages, skipped)
+}
 
+// writeOutputs 按输出模式输出json或生成报告
+func writeOutputs(packages utils.Packages, skipped []*errs.SkippedFile) error {
 	switch outputMode {
 	case outputModeOnlyJson:
+		if err := checkExportFlags(); err != nil {
+			return err
+		}
 		// 如果是只要json, 完成直接退出
-		if err = utils.MarshalJson(os.Stdout, packages); err != nil {
-			log.Fatalf("Failed to generate json. err: %v\n", err)
+		if err := utils.MarshalJson(os.Stdout, packages); err != nil {
+			return fmt.Errorf("failed to generate json. err: %w", err)
 		}
-	case outputModeOnlyFull:
-		buildFullReport(packages)
-	case outputModeOnlyDiff:
-		buildDiffReport(packages)
-	case outputModeAll:
-		buildFullReport(packages)
-		buildDiffReport(packages)
+		return writeExports(packages, nil)
 	default:
-		log.Fatalf("Unsupported output mode. [%s]", outputMode)
+		return buildReports(packages, skipped)
 	}
+}
 
-	return
+// stdoutMode 判断输出模式是否将结果输出到stdout
+func stdoutMode() bool {
+	return outputMode == outputModeOnlyJson || outputMode == outputModeOnlyText || outputMode == outputModeOnlyMD
+}
+
+// buildReports 按输出模式生成全量/增量报告，并按门禁选项判定覆盖率
+func buildReports(packages utils.Packages, skipped []*errs.SkippedFile) error {
+	stdout := outputMode == outputModeOnlyText || outputMode == outputModeOnlyMD
+	full := outputMode == outputModeOnlyFull || outputMode == outputModeAll
+	diff := outputMode == outputModeOnlyDiff || outputMode == outputModeAll
+	if !stdout && !full && !diff {
+		return fmt.Errorf("unsupported output mode. [%s]", outputMode)
+	}
+	if _, err := types.ParseSortMethod(sortMethod); err != nil {
+		return err
+	}
+	if err := checkExportFlags(); err != nil {
+		return err
+	}
+
+	var diffPackages utils.Packages
+	results := make([]*gate.Result, 0, 2)
+	evaluations := make([]*gate.Evaluation, 0, 2)
+	if stdout || full {
+		if stdout {
+			if err := writeStdoutReport(packages); err != nil {
+				return err
+			}
+		} else if err := buildFullReport(packages, skipped); err != nil {
+			return err
+		}
+		result, err := evaluateGate(gate.RuleFull, packages)
+		if err != nil {
+			return err
+		}
+		if result != nil {
+			results = append(results, result)
+			evaluations = append(evaluations, &gate.Evaluation{Rule: result.Rule, Packages: packages})
+		}
+	}
+	if diff {
+		var err error
+		if diffPackages, err = buildDiffReport(packages, skipped); err != nil {
+			return err
+		}
+		result, err := evaluateGate(gate.RuleDiff, diffPackages)
+		if err != nil {
+			return err
+		}
+		if result != nil {
+			results = append(results, result)
+			evaluations = append(evaluations, &gate.Evaluation{Rule: result.Rule, Packages: diffPackages})
+		}
+	}
+	if err := writeExports(packages, diffPackages); err != nil {
+		return err
+	}
+	if len(historyDir) > 0 {
+		if err := recordHistory(packages, diffPackages); err != nil {
+			return err
+		}
+	}
+	if err := writeGateJUnit(evaluations); err != nil {
+		return err
+	}
+	return gate.Check(results)
+}
+
+// writeStdoutReport 按输出模式将全量的文本或Markdown报告输出到stdout
+func writeStdoutReport(packages utils.Packages) error {
+	var err error
+	if outputMode == outputModeOnlyMD {
+		err = report.WriteMarkdown(os.Stdout, packages, types.SortMethod(sortMethod))
+	} else {
+		err = report.WriteText(os.Stdout, packages, types.SortMethod(sortMethod))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate %s report. err: %w", outputMode, err)
+	}
+	return nil
 }
 
-func buildFullReport(packages utils.Packages) {
+func buildFullReport(packages utils.Packages, skipped []*errs.SkippedFile) error {
 	newPkg := make(utils.Packages, 0)
 	if err := copier.CopyWithOption(&newPkg, &packages, copier.Option{DeepCopy: true}); err != nil {
-		log.Fatalf("Handle packages data failed. err: %v\n", err)
+		return fmt.Errorf("handle packages data failed. err: %w", err)
 	}
 
-	currentBranch, err := utils.GetCurrentBranch()
-	if err != nil {
-		log.Fatalf(err.Error())
+	branch := currentBranch
+	if len(branch) == 0 {
+		var err error
+		if branch, err = utils.GetCurrentBranch(); err != nil {
+			log.Printf("Unknown current branch. err: %v\n", err)
+		}
 	}
 	param := &report.GenerateHTMLParam{
 		Packages:     newPkg,
 		CSS:          css,
 		Dir:          ".",
 		FileName:     utils.FullHTML,
-		BranchesInfo: &metadata.BranchesInfo{CurrentBranchName: currentBranch},
+		BranchesInfo: &metadata.BranchesInfo{CurrentBranchName: branch},
+		GitFallback:  gitSource,
+		KeepGoing:    keepGoing,
+		Skipped:      skipped,
+		Sort:         types.SortMethod(sortMethod),
 	}
-	if err = report.GenerateHTML(param); err != nil {
-		log.Fatalf("Failed to generate full-coverage-report. err: %v\n", err)
-		return
+	if err := report.GenerateHTML(param); err != nil {
+		return fmt.Errorf("failed to generate full-coverage-report. err: %w", err)
 	}
 	log.Println("Generate full-coverage-report success.")
+	return nil
 }
 
-func buildDiffReport(packages utils.Packages) {
-	var (
-		diffPackages utils.Packages
-		branchesInfo *metadata.BranchesInfo
-		err          error
-	)
+func buildDiffReport(packages utils.Packages, skipped []*errs.SkippedFile) (utils.Packages, error) {
+	diffPackages, branchesInfo, err := trimDiff(packages)
+	if err != nil {
+		return nil, err
+	}
 
+	param := &report.GenerateHTMLParam{
+		Packages:     diffPackages,
+		CSS:          css,
+		Dir:          ".",
+		FileName:     utils.DiffHTML,
+		BranchesInfo: branchesInfo,
+		GitFallback:  gitSource,
+		KeepGoing:    keepGoing,
+		Skipped:      skipped,
+		Sort:         types.SortMethod(sortMethod),
+	}
+	if err = report.GenerateHTML(param); err != nil {
+		return nil, fmt.Errorf("failed to generate diff-coverage-report. err: %w", err)
+	}
+	log.Println("Generate diff-coverage-report success.")
+	return diffPackages, nil
+}
+
+// trimDiff 按差异信息裁剪出增量覆盖率数据。
+// 指定了差异信息文件时从文件加载，否则按 -c、-t、-i 选项调用 git 获取。
+func trimDiff(packages utils.Packages) (diffPackages utils.Packages, branchesInfo *metadata.BranchesInfo, err error) {
+	var rules metadata.ReservedRules
 	if len(difference) > 0 {
 		info, err := utils.LoadReservedInfo(difference)
 		if err != nil {
-			return
+			return nil, nil, err
 		}
 		branchesInfo = info.Branches
-		diffPackages, err = trim.TrimPackages(packages, info.Rules)
-		if err != nil {
-			log.Fatalf("Failed to trim diff-coverage. err: %v\n", err)
-			return
-		}
+		rules = info.Rules
 	} else {
 		hashIdsRange, err := parseHashIdsRange()
 		if err != nil {
-			log.Fatalln(err)
+			return nil, nil, err
 		}
 		diffMgr, err := diff.Do(currentBranch, targetBranch, hashIdsRange)
 		if err != nil {
-			log.Fatalln(err)
+			return nil, nil, err
 		}
 		branchesInfo = &metadata.BranchesInfo{
 			TargetBranchName:  diffMgr.TargetBranch,
@@ -135,23 +263,11 @@ func buildDiffReport(packages utils.Packages) {
 			StartHashID:       diffMgr.CommitHashIdRange[0],
 			EndHashID:         diffMgr.CommitHashIdRange[1],
 		}
-		diffPackages, err = trim.TrimPackages(packages, diffMgr.ConvToReservedRules())
-		if err != nil {
-			log.Fatalf("Failed to trim diff-coverage. err: %v\n", err)
-			return
-		}
+		rules = diffMgr.ConvToReservedRules()
 	}
 
-	param := &report.GenerateHTMLParam{
-		Packages:     diffPackages,
-		CSS:          css,
-		Dir:          ".",
-		FileName:     utils.DiffHTML,
-		BranchesInfo: branchesInfo,
+	if diffPackages, err = trim.TrimPackages(packages, rules); err != nil {
+		return nil, nil, fmt.Errorf("failed to trim diff-coverage. err: %w", err)
 	}
-	if err = report.GenerateHTML(param); err != nil {
-		log.Fatalf("Failed to generate diff-coverage-report. err: %v\n", err)
-		return
-	}
-	log.Println("Generate diff-coverage-report success.")
+	return
 }
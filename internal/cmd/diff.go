@@ -3,7 +3,6 @@ package cmd
 import (
 	"bufio"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 
@@ -15,13 +14,11 @@ var diffCmd = &cobra.Command{
 	Use:   "diff",
 	Short: "",
 	Long:  "",
-	Run: func(cmd *cobra.Command, args []string) {
-		runDiff()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDiff()
 	},
 }
 
-const defaultTargetBranch = "master"
-
 var (
 	currentBranch     string
 	targetBranch      string
@@ -30,20 +27,20 @@ var (
 
 func init() {
 	diffCmd.Flags().StringVarP(&currentBranch, "current-branch", "c", "", "The current branch under test")
-	diffCmd.Flags().StringVarP(&targetBranch, "target-branch", "t", defaultTargetBranch, "The branch that was compared to find the difference")
+	diffCmd.Flags().StringVarP(&targetBranch, "target-branch", "t", diff.DefaultTargetBranch, "The branch that was compared to find the difference")
 	diffCmd.Flags().StringVarP(&hashIdsRangeParam, "hash-ids-range", "i", "", "The range of hash-ids that need to be reserved. format: 'start-hash-id,end-hash-id'")
 
 	rootCmd.AddCommand(diffCmd)
 }
 
-func runDiff() {
+func runDiff() error {
 	hashIdsRange, err := parseHashIdsRange()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	diffMgr, err := diff.Do(currentBranch, targetBranch, hashIdsRange)
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
 	content := diffMgr.ConvToOutputFormat()
 
@@ -52,9 +49,10 @@ func runDiff() {
 
 	for _, v := range content {
 		if _, err = fmt.Fprintln(out, v); err != nil {
-			log.Fatalf("failed to write diff-info to stdout. err: %v\n", err)
+			return fmt.Errorf("failed to write diff-info to stdout. err: %w", err)
 		}
 	}
+	return nil
 }
 
 func parseHashIdsRange() ([]string, error) {
@@ -62,7 +60,7 @@ func parseHashIdsRange() ([]string, error) {
 	if len(hashIdsRangeParam) > 0 {
 		tmp := strings.Split(hashIdsRangeParam, ",")
 		if len(tmp) != 2 {
-			return nil, fmt.Errorf("invalid hash-ids range fomart. [%s]\n", hashIdsRangeParam)
+			return nil, fmt.Errorf("invalid hash-ids range fomart. [%s]", hashIdsRangeParam)
 		}
 		hashIdsRange = append(hashIdsRange, tmp[0], tmp[1])
 	}
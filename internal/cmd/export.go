@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/lamber92/go-cover/internal/gate"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sonarFull     string
+	sonarDiff     string
+	sourceRoot    string
+	coberturaFull string
+	coberturaDiff string
+	sarifPath     string
+	ghAnnotations bool
+)
+
+// addExportFlags 为命令添加供其他系统导入的输出选项
+func addExportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sonarFull, "sonar-full", "", "Write the full coverage as SonarQube generic coverage XML to the file-path")
+	cmd.Flags().StringVar(&sonarDiff, "sonar-diff", "", "Write the diff coverage as SonarQube generic coverage XML to the file-path")
+	cmd.Flags().StringVar(&coberturaFull, "cobertura-full", "", "Write the full coverage as Cobertura XML to the file-path")
+	cmd.Flags().StringVar(&coberturaDiff, "cobertura-diff", "", "Write the diff coverage as Cobertura XML to the file-path")
+	cmd.Flags().StringVar(&sourceRoot, "source-root", ".", "The project root that the file paths in the SonarQube XML, Cobertura XML, SARIF and annotations are relative to")
+	// --sonar-root 是 --source-root 的旧名称，保留以兼容已有的脚本
+	cmd.Flags().StringVar(&sourceRoot, "sonar-root", ".", "Deprecated alias of --source-root")
+	_ = cmd.Flags().MarkDeprecated("sonar-root", "use --source-root instead")
+	cmd.Flags().StringVar(&sarifPath, "sarif", "", "Write every range of uncovered new lines as a SARIF 2.1.0 result to the file-path")
+	cmd.Flags().BoolVar(&ghAnnotations, "github-annotations", false, "Print every range of uncovered new lines as a GitHub Actions ::warning (::error when below --min-diff) command to stdout")
+}
+
+// checkExportFlags 检查输出选项能否与输出模式一起使用，需要在输出任何内容之前调用
+func checkExportFlags() error {
+	// 注解输出到 stdout，会混入同样输出到 stdout 的 json 或报告中
+	if ghAnnotations && stdoutMode() {
+		return fmt.Errorf("--github-annotations cannot be used with output mode [%s], which also writes to stdout", outputMode)
+	}
+	// json-only 模式不判定门禁，没有可以写入的结果
+	if len(gateJUnit) > 0 && outputMode == outputModeOnlyJson {
+		return fmt.Errorf("--junit cannot be used with output mode [%s], which does not evaluate the coverage gates", outputMode)
+	}
+	return nil
+}
+
+// writeExports 按输出选项输出全量与增量数据及未覆盖新代码行的注解，diffPackages 为空且需要增量数据时按差异裁剪
+func writeExports(packages, diffPackages utils.Packages) error {
+	annotate := len(sarifPath) > 0 || ghAnnotations
+	if (len(sonarDiff) > 0 || len(coberturaDiff) > 0 || annotate) && diffPackages == nil {
+		var err error
+		if diffPackages, _, err = trimDiff(packages); err != nil {
+			return err
+		}
+	}
+	if len(sonarFull) > 0 {
+		if err := writeExportFile(sonarFull, func(w io.Writer) error {
+			return report.WriteSonar(w, packages, sourceRoot)
+		}); err != nil {
+			return err
+		}
+	}
+	if len(sonarDiff) > 0 {
+		if err := writeExportFile(sonarDiff, func(w io.Writer) error {
+			return report.WriteSonar(w, diffPackages, sourceRoot)
+		}); err != nil {
+			return err
+		}
+	}
+	if len(coberturaFull) > 0 {
+		if err := writeExportFile(coberturaFull, func(w io.Writer) error {
+			return report.WriteCobertura(w, packages, sourceRoot)
+		}); err != nil {
+			return err
+		}
+	}
+	if len(coberturaDiff) > 0 {
+		if err := writeExportFile(coberturaDiff, func(w io.Writer) error {
+			return report.WriteCobertura(w, diffPackages, sourceRoot)
+		}); err != nil {
+			return err
+		}
+	}
+	if annotate {
+		param := &report.AnnotationParam{Root: sourceRoot}
+		if gateMinDiff > 0 {
+			metric, err := metadata.ParseMetric(gateMetric)
+			if err != nil {
+				return err
+			}
+			param.Gate = gate.Evaluate(&gate.Rule{Name: gate.RuleDiff, Metric: metric, Min: gateMinDiff}, diffPackages)
+		}
+		if len(sarifPath) > 0 {
+			if err := writeExportFile(sarifPath, func(w io.Writer) error {
+				return report.WriteSARIF(w, diffPackages, param)
+			}); err != nil {
+				return err
+			}
+		}
+		if ghAnnotations {
+			if err := report.WriteGitHubAnnotations(os.Stdout, diffPackages, param); err != nil {
+				return fmt.Errorf("failed to write github annotations. err: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeExportFile 创建文件并由 write 写入内容
+func writeExportFile(path string, write func(w io.Writer) error) error {
+	file, err := utils.CreateFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err = write(file); err != nil {
+		return fmt.Errorf("failed to write %s. err: %w", path, err)
+	}
+	log.Printf("Write %s success.\n", path)
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"io"
+	"log"
+
+	"github.com/lamber92/go-cover/internal/gate"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gateMetric  string
+	gateMinFull float64
+	gateMinDiff float64
+	gateJUnit   string
+)
+
+// addGateFlags 为命令添加覆盖率门禁选项
+func addGateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&gateMetric, "metric", string(metadata.MetricStatement), "The coverage metric used by thresholds. Options: 'statement', 'line', 'block' or 'branch'")
+	cmd.Flags().Float64Var(&gateMinFull, "min-full", 0, "The minimum full coverage percentage; fail when not reached. Default: disabled")
+	cmd.Flags().Float64Var(&gateMinDiff, "min-diff", 0, "The minimum diff coverage percentage; fail when not reached. Default: disabled")
+	cmd.Flags().StringVar(&gateJUnit, "junit", "", "Write the gate results as JUnit XML to the file-path, one testsuite per package and one testcase per threshold")
+}
+
+// evaluateGate 按门禁选项判定覆盖率，未设置阈值时返回 nil
+func evaluateGate(name string, packages utils.Packages) (*gate.Result, error) {
+	min := gateMinFull
+	if name == gate.RuleDiff {
+		min = gateMinDiff
+	}
+	if min <= 0 {
+		return nil, nil
+	}
+	metric, err := metadata.ParseMetric(gateMetric)
+	if err != nil {
+		return nil, err
+	}
+	result := gate.Evaluate(&gate.Rule{Name: name, Metric: metric, Min: min}, packages)
+	log.Println(result)
+	return result, nil
+}
+
+// writeGateJUnit 按 --junit 选项将门禁判定结果写为 JUnit XML
+func writeGateJUnit(evaluations []*gate.Evaluation) error {
+	if len(gateJUnit) == 0 {
+		return nil
+	}
+	return writeExportFile(gateJUnit, func(w io.Writer) error {
+		return gate.WriteJUnit(w, evaluations)
+	})
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lamber92/go-cover/internal/history"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	historyOutputText = "text" // 输出文本到stdout
+	historyOutputHTML = "html" // 输出 history.html
+)
+
+var (
+	historyBranch string
+	historyOutput string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "history ${history-dir}",
+	Long:  "history ${history-dir}",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHistory(args)
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVarP(&historyBranch, "branch", "b", "", "Only show the snapshots of the branch. Default: all branches")
+	historyCmd.Flags().StringVarP(&historyOutput, "output-mode", "o", historyOutputText, "Options: 'text' or 'html'; Default: 'text'")
+
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected the history directory")
+	}
+	store, err := history.Open(args[0])
+	if err != nil {
+		return err
+	}
+	snaps, err := store.List(historyBranch)
+	if err != nil {
+		return err
+	}
+
+	switch historyOutput {
+	case historyOutputText:
+		return report.WriteHistoryText(os.Stdout, snaps)
+	case historyOutputHTML:
+		file, err := utils.CreateFile(".", utils.HistoryHTML)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err = report.WriteHistoryHTML(file, snaps); err != nil {
+			return fmt.Errorf("failed to generate history report. err: %w", err)
+		}
+		log.Println("Generate history report success.")
+		return nil
+	default:
+		return fmt.Errorf("unsupported output mode. [%s]", historyOutput)
+	}
+}
+
+// recordHistory 将本次报告的覆盖率摘要追加到历史库，并提示相对上一次快照的覆盖率下降项
+func recordHistory(packages, diffPackages utils.Packages) error {
+	metric, err := metadata.ParseMetric(gateMetric)
+	if err != nil {
+		return err
+	}
+	store, err := history.Open(historyDir)
+	if err != nil {
+		return err
+	}
+
+	snap := history.NewSnapshot(packages, diffPackages, metric)
+	if snap.Branch = currentBranch; len(snap.Branch) == 0 {
+		if snap.Branch, err = utils.GetCurrentBranch(); err != nil {
+			log.Printf("Unknown current branch. err: %v\n", err)
+		}
+	}
+	if snap.Commit, err = utils.GetHeadCommit(); err != nil {
+		log.Printf("Unknown head commit. err: %v\n", err)
+	}
+
+	// 分支未知时 List 会返回所有分支的快照，无法找到同一分支的上一次快照
+	if len(snap.Branch) == 0 {
+		log.Println("Skip the coverage regression check: unknown current branch.")
+		return store.Append(snap)
+	}
+	snaps, err := store.List(snap.Branch)
+	if err != nil {
+		return err
+	}
+	if err = store.Append(snap); err != nil {
+		return err
+	}
+	if n := len(snaps); n > 0 {
+		for _, c := range history.Regressions(snaps[n-1], snap) {
+			log.Printf("Coverage regression[%s]: %.2f%% -> %.2f%% (%+.2f%%)\n", c.Name, c.Previous, c.Current, c.Delta())
+		}
+	}
+	return nil
+}
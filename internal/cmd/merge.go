@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/lamber92/go-cover/internal/profile"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	dataFormatJson    = "json"    // go-cover 的 json
+	dataFormatProfile = "profile" // go test -coverprofile 的文本格式
+)
+
+var (
+	dataFormat string
+	mergeMode  string
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "merge ${coverage.json / coverage.profile...}",
+	Long:  "merge ${coverage.json / coverage.profile...}",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMerge(args)
+	},
+}
+
+func init() {
+	mergeCmd.Flags().StringVar(&dataFormat, "format", dataFormatJson, "Options: 'json' or 'profile' (go test -coverprofile format); Default: 'json'")
+	mergeCmd.Flags().StringVar(&mergeMode, "mode", "", "Convert every file to the cover mode before merging, e.g. 'set' to merge set and count data. Default: keep the modes, which must be compatible")
+	mergeCmd.Flags().BoolVarP(&keepGoing, "keep-going", "k", false, "Skip the source files of profiles that are missing or cannot be parsed")
+	mergeCmd.Flags().IntVar(&workers, "workers", 0, "The number of files converted concurrently. Default: the number of CPUs")
+
+	rootCmd.AddCommand(mergeCmd)
+}
+
+// runMerge 合并多份 go-cover json 或 profile，输出到 stdout
+func runMerge(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected at least one coverage json or profile")
+	}
+	packages, skipped, err := loadCoverage(args, mergeMode)
+	if err != nil {
+		return err
+	}
+	for _, v := range skipped {
+		log.Printf("Skip file[%s]. reason: %s\n", v.File, v.Reason)
+	}
+	return writeData(os.Stdout, packages)
+}
+
+// writeData 按 --format 选项输出覆盖率数据
+func writeData(w io.Writer, packages utils.Packages) error {
+	switch dataFormat {
+	case dataFormatJson:
+		return utils.MarshalJson(w, packages)
+	case dataFormatProfile:
+		return profile.Write(w, packages, "")
+	default:
+		return fmt.Errorf("unsupported format. [%s]", dataFormat)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lamber92/go-cover/internal/diff"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var reportSubtract []string
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "report ${coverage.json}",
+	Long:  "report ${coverage.json}",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReport(args)
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVarP(&outputMode, "output-mode", "o", outputModeAll, "Options: 'full-only', 'diff-only', 'text-only' or 'markdown-only'; Default: 'all'")
+	reportCmd.Flags().StringVarP(&css, "css-format", "f", "", "The file-path witch record customized report themes within CSS-format")
+	reportCmd.Flags().StringVarP(&difference, "diff", "d", "", "The file-path witch record code difference information")
+	reportCmd.Flags().StringVarP(&currentBranch, "current-branch", "c", "", "The current branch under test")
+	reportCmd.Flags().StringVarP(&targetBranch, "target-branch", "t", diff.DefaultTargetBranch, "The branch that was compared to find the difference")
+	reportCmd.Flags().StringVarP(&hashIdsRangeParam, "hash-ids-range", "i", "", "The range of hash-ids that need to be reserved. format: 'start-hash-id,end-hash-id'")
+	reportCmd.Flags().BoolVar(&gitSource, "git-source", false, "Read the original source from git at the recorded commit when a source file has changed since conversion")
+	reportCmd.Flags().BoolVarP(&keepGoing, "keep-going", "k", false, "Render what is available when source files are missing, and list them in the report")
+	reportCmd.Flags().StringVar(&sortMethod, "sort", string(types.SortByCoverage), "The order of functions in reports. Options: 'coverage', 'uncovered', 'crap', 'complexity' or 'name'")
+	reportCmd.Flags().StringVar(&historyDir, "history", "", "The directory of the coverage history store; a snapshot is appended to it after the reports are built")
+	reportCmd.Flags().StringSliceVar(&reportSubtract, "subtract", nil, "The go-cover json files (e.g. an earlier snapshot of collect --interval) whose execution counts are subtracted, so that only what ran afterwards is reported")
+	addGateFlags(reportCmd)
+	addExportFlags(reportCmd)
+
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReport(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected at least one coverage json")
+	}
+
+	// 多个 json 文件会被合并为一份报告
+	packages, err := utils.ReadPackages(args)
+	if err != nil {
+		return fmt.Errorf("failed to load coverage json. err: %w", err)
+	}
+	if len(reportSubtract) > 0 {
+		old, err := utils.ReadPackages(reportSubtract)
+		if err != nil {
+			return fmt.Errorf("failed to load coverage json. err: %w", err)
+		}
+		if err = packages.Subtract(old); err != nil {
+			return fmt.Errorf("failed to subtract coverage. err: %w", err)
+		}
+	}
+	return buildReports(packages, nil)
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"log"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -11,10 +13,31 @@ var rootCmd = &cobra.Command{
 	Short: "go-cover is a converter for go coverage profile --> html report",
 	Long: `go-cover is a converter for go coverage profile --> html report
 	the command is: convert ${coverage.profile} --report ${report-mode}`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// exitError 是需要以指定退出码结束进程的错误，如被转发的测试失败
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatalln(err)
+		log.Println(err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		os.Exit(1)
 	}
 }
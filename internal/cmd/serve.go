@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/convert"
+	"github.com/lamber92/go-cover/internal/errs"
+	"github.com/lamber92/go-cover/internal/report"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/serve"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr     string
+	serveInterval time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "serve ${coverage.profile|coverage.json...}",
+	Long:  "serve ${coverage.profile|coverage.json...}",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServe(args)
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "The address to listen on")
+	serveCmd.Flags().DurationVar(&serveInterval, "interval", serve.DefaultInterval, "The interval of checking the files for changes")
+	serveCmd.Flags().StringVarP(&css, "css-format", "f", "", "The file-path witch record customized report themes within CSS-format")
+	serveCmd.Flags().BoolVar(&gitSource, "git-source", false, "Read the original source from git at the recorded commit when a source file has changed since conversion")
+	serveCmd.Flags().BoolVarP(&keepGoing, "keep-going", "k", false, "Skip the source files that are missing or cannot be parsed, and list them in the report")
+	serveCmd.Flags().StringVar(&sortMethod, "sort", string(types.SortByCoverage), "The order of functions in reports. Options: 'coverage', 'uncovered', 'crap', 'complexity' or 'name'")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected at least one coverage profile or json")
+	}
+	sortBy, err := types.ParseSortMethod(sortMethod)
+	if err != nil {
+		return err
+	}
+	server, err := serve.New(&serve.Param{
+		Files:    args,
+		Load:     func() (utils.Packages, []*errs.SkippedFile, error) { return loadCoverage(args, "") },
+		Interval: serveInterval,
+		Render: report.GenerateHTMLParam{
+			CSS:         css,
+			GitFallback: gitSource,
+			KeepGoing:   keepGoing,
+			Sort:        sortBy,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+	go server.Watch(stop)
+	httpServer := &http.Server{Addr: serveAddr, Handler: server}
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt)
+		<-signals
+		close(stop)
+		_ = httpServer.Shutdown(context.Background())
+	}()
+
+	log.Printf("Serving coverage report on http://%s\n", serveAddr)
+	if err = httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// loadCoverage 加载并合并覆盖率数据。.json 文件按 go-cover 的中间态 json 读取，其余文件按 Go-Coverage-Profile 转换。
+// mode 非空时先将每个文件的数据转换为该覆盖率模式再合并
+func loadCoverage(files []string, mode string) (utils.Packages, []*errs.SkippedFile, error) {
+	var packages utils.Packages
+	var skipped []*errs.SkippedFile
+	for _, file := range files {
+		var ps utils.Packages
+		var err error
+		if filepath.Ext(file) == ".json" {
+			ps, err = utils.ReadPackages([]string{file})
+		} else {
+			var s []*errs.SkippedFile
+			ps, s, err = convert.Do(file, &convert.Param{KeepGoing: keepGoing, Workers: workers})
+			skipped = append(skipped, s...)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range ps {
+			if mode != "" {
+				if err = p.SetMode(mode); err != nil {
+					return nil, nil, fmt.Errorf("failed to convert %s. err: %w", file, err)
+				}
+			}
+			if err = packages.AppendPackage(p); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return packages, skipped, nil
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/lamber92/go-cover/internal/convert"
+	"github.com/lamber92/go-cover/internal/diff"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testCoverPkg  string
+	testCoverMode string
+	testProfile   string
+	testPerTest   bool
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "test [packages] [-- go test flags]",
+	Long: `test [packages] [-- go test flags]
+	run go test with coverage for the module (or workspace), then convert the profile and build the reports`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pkgs, testArgs := args, []string(nil)
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			pkgs, testArgs = args[:dash], args[dash:]
+		}
+		return runTest(pkgs, testArgs)
+	},
+}
+
+func init() {
+	testCmd.Flags().StringVar(&testCoverPkg, "coverpkg", "", "The packages passed to go test -coverpkg. Default: all packages of the module or workspace")
+	testCmd.Flags().StringVar(&testCoverMode, "covermode", "", "The cover mode passed to go test. Default: 'atomic' with -race, otherwise 'set'")
+	testCmd.Flags().StringVar(&testProfile, "profile", "", "Keep the coverage profile at the file-path. Default: a temporary file that is removed")
+	testCmd.Flags().BoolVar(&testPerTest, "per-test", false, "Run every test on its own to record which tests cover each line into the json and the reports")
+	testCmd.Flags().StringVarP(&outputMode, "output-mode", "o", outputModeAll, "Options: 'full-only', 'diff-only', 'json-only', 'text-only' or 'markdown-only'; Default: 'all'")
+	testCmd.Flags().StringVarP(&css, "css-format", "f", "", "The file-path witch record customized report themes within CSS-format")
+	testCmd.Flags().StringVarP(&difference, "diff", "d", "", "The file-path witch record code difference information")
+	testCmd.Flags().StringVarP(&currentBranch, "current-branch", "c", "", "The current branch under test")
+	testCmd.Flags().StringVarP(&targetBranch, "target-branch", "t", diff.DefaultTargetBranch, "The branch that was compared to find the difference")
+	testCmd.Flags().StringVarP(&hashIdsRangeParam, "hash-ids-range", "i", "", "The range of hash-ids that need to be reserved. format: 'start-hash-id,end-hash-id'")
+	testCmd.Flags().BoolVar(&embedSrc, "embed-source", false, "Embed the compressed source of every covered file into the json, so that reports can be rendered offline")
+	testCmd.Flags().BoolVar(&gitSource, "git-source", false, "Read the original source from git at the recorded commit when a source file has changed since conversion")
+	testCmd.Flags().BoolVarP(&keepGoing, "keep-going", "k", false, "Skip the source files that are missing or cannot be parsed, and list them in the report")
+	testCmd.Flags().StringVar(&sortMethod, "sort", string(types.SortByCoverage), "The order of functions in reports. Options: 'coverage', 'uncovered', 'crap', 'complexity' or 'name'")
+	testCmd.Flags().StringVar(&historyDir, "history", "", "The directory of the coverage history store; a snapshot is appended to it after the reports are built")
+	testCmd.Flags().IntVar(&workers, "workers", 0, "The number of files converted concurrently. Default: the number of CPUs")
+	addGateFlags(testCmd)
+	addExportFlags(testCmd)
+
+	rootCmd.AddCommand(testCmd)
+}
+
+func runTest(pkgs, testArgs []string) error {
+	if len(pkgs) == 0 {
+		pkgs = []string{"./..."}
+	}
+	coverPkgs, err := testCoverPkgs()
+	if err != nil {
+		return err
+	}
+	coverMode := testCoverMode
+	if coverMode == "" {
+		coverMode = defaultCoverMode(testArgs)
+	}
+
+	profile := testProfile
+	if profile == "" {
+		file, err := ioutil.TempFile("", "go-cover-*.out")
+		if err != nil {
+			return err
+		}
+		file.Close()
+		profile = file.Name()
+		defer os.Remove(profile)
+	}
+
+	// 结果输出到stdout时，测试输出转到stderr，避免混在一起
+	var testStdout io.Writer = os.Stdout
+	if stdoutMode() {
+		testStdout = os.Stderr
+	}
+	testErr := runGoTest(profile, coverMode, coverPkgs, testArgs, pkgs, testStdout)
+	if info, err := os.Stat(profile); err != nil || info.Size() == 0 {
+		if testErr != nil {
+			return testExitError(testErr)
+		}
+		return fmt.Errorf("no coverage profile was produced")
+	}
+
+	packages, skipped, err := convert.Do(profile, &convert.Param{EmbedSource: embedSrc, KeepGoing: keepGoing, Workers: workers})
+	if err != nil {
+		return err
+	}
+	for _, v := range skipped {
+		log.Printf("Skip file[%s]. reason: %s\n", v.File, v.Reason)
+	}
+	if testPerTest {
+		if err = attributeTests(packages, coverMode, coverPkgs, testArgs, pkgs); err != nil {
+			return err
+		}
+	}
+	// 测试失败时仍然生成报告，之后以测试的退出码结束
+	if err = writeOutputs(packages, skipped); err != nil {
+		if testErr == nil {
+			return err
+		}
+		log.Println(err)
+	}
+	if testErr != nil {
+		return testExitError(testErr)
+	}
+	return nil
+}
+
+// testCoverPkgs 返回 -coverpkg 的包列表：未指定时为当前模块(或工作区中所有模块)的所有包
+func testCoverPkgs() ([]string, error) {
+	if testCoverPkg != "" {
+		return strings.Split(testCoverPkg, ","), nil
+	}
+	cmd := exec.Command("go", "list", "-m")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modules. err: %w, output: %s", err, stderr.String())
+	}
+	var pkgs []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if module := strings.TrimSpace(scanner.Text()); module != "" {
+			pkgs = append(pkgs, module+"/...")
+		}
+	}
+	return pkgs, nil
+}
+
+// defaultCoverMode 返回默认的覆盖率模式，开启竞态检测时 go test 要求使用 atomic
+func defaultCoverMode(testArgs []string) string {
+	for _, arg := range testArgs {
+		if arg == "-race" || arg == "--race" || arg == "-race=true" {
+			return "atomic"
+		}
+	}
+	return "set"
+}
+
+// testExitError 将测试失败转换为使用相同退出码的错误
+func testExitError(err error) error {
+	code := 1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	}
+	return &exitError{code: code, err: err}
+}
+
+// attributeTests 逐个运行测试并记录每个测试执行过的覆盖块。
+// coverMode 与整体运行时一致，以满足 -race 等测试参数对覆盖率模式的要求。
+func attributeTests(packages utils.Packages, coverMode string, coverPkgs, testArgs, pkgs []string) error {
+	tests, err := listTests(testArgs, pkgs)
+	if err != nil {
+		return err
+	}
+	skipped, err := convert.AttributeTests(packages, tests, func(t, profile string) {
+		pkg, name := metadata.SplitTestID(t)
+		log.Printf("Run test[%s] for attribution.\n", t)
+		args := append(append([]string{}, testArgs...), "-run", "^"+regexp.QuoteMeta(name)+"$")
+		// 单个测试失败时 go test 仍会写入 profile，记录它执行过的代码
+		if err := runGoTest(profile, coverMode, coverPkgs, args, []string{pkg}, ioutil.Discard); err != nil {
+			log.Println(err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, t := range skipped {
+		log.Printf("Skip attribution of test[%s]: no coverage profile was produced.\n", t)
+	}
+	if len(skipped) > 0 && len(skipped) == len(tests) {
+		return fmt.Errorf("no test produced a coverage profile for attribution")
+	}
+	return nil
+}
+
+// listTests 通过 go test -list 列出各个包中的测试，返回测试标识(见 metadata.TestID)
+func listTests(testArgs, pkgs []string) ([]string, error) {
+	args := append(append([]string{"test", "-list", "^Test"}, testArgs...), pkgs...)
+	cmd := exec.Command("go", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tests. err: %w, output: %s", err, stderr.String())
+	}
+	return metadata.ParseTestList(bytes.NewReader(output))
+}
+
+// runGoTest 执行 go test 并将覆盖率数据写入 profile，测试输出转发到 stdout 与标准错误
+func runGoTest(profile, coverMode string, coverPkgs, testArgs, pkgs []string, stdout io.Writer) error {
+	args := []string{"test", "-covermode=" + coverMode, "-coverprofile=" + profile}
+	if len(coverPkgs) > 0 {
+		args = append(args, "-coverpkg="+strings.Join(coverPkgs, ","))
+	}
+	args = append(args, testArgs...)
+	args = append(args, pkgs...)
+	cmd := exec.Command("go", args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go %s: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
@@ -1,11 +1,10 @@
 package cmd
 
 import (
-	"log"
+	"fmt"
 	"os"
 
 	"github.com/lamber92/go-cover/internal/trim"
-	"github.com/lamber92/go-cover/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -13,34 +12,28 @@ var trimCmd = &cobra.Command{
 	Use:   "trim",
 	Short: "trim ${coverage.json}",
 	Long:  "trim ${coverage.json}",
-	Run: func(cmd *cobra.Command, args []string) {
-		runTrim(args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTrim(args)
 	},
 }
 
 func init() {
 	trimCmd.Flags().StringVarP(&difference, "diff", "d", "", "the file-path witch record code difference information")
+	trimCmd.Flags().StringVar(&dataFormat, "format", dataFormatJson, "Options: 'json' or 'profile' (go test -coverprofile format); Default: 'json'")
 
 	rootCmd.AddCommand(trimCmd)
 }
 
-func runTrim(args []string) {
+func runTrim(args []string) error {
 	if len(args) == 0 {
-		log.Fatalln("Expected at least one coverage json.")
-		return
+		return fmt.Errorf("expected at least one coverage json")
 	}
 	if len(difference) == 0 {
-		log.Fatalln("difference path is empty. skip generating diff-coverage-report.")
-		return
+		return fmt.Errorf("difference path is empty")
 	}
 	packages, err := trim.Do(args[0], difference)
 	if err != nil {
-		log.Fatalln(err)
-		return
+		return err
 	}
-	if err = utils.MarshalJson(os.Stdout, packages); err != nil {
-		log.Fatalln(err)
-		return
-	}
-	return
+	return writeData(os.Stdout, packages)
 }
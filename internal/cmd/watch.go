@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/convert"
+	"github.com/lamber92/go-cover/internal/diff"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report"
+	"github.com/lamber92/go-cover/internal/utils"
+	"github.com/lamber92/go-cover/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval  time.Duration
+	watchCoverMode string
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "watch [packages] [-- go test flags]",
+	Long: `watch [packages] [-- go test flags]
+	re-run go test with coverage for the packages whose go files changed, and print the uncovered new lines`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		patterns, testArgs := args, []string(nil)
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			patterns, testArgs = args[:dash], args[dash:]
+		}
+		return runWatch(patterns, testArgs)
+	},
+}
+
+func init() {
+	watchCmd.Flags().StringVarP(&difference, "diff", "d", "", "The file-path witch record code difference information")
+	watchCmd.Flags().StringVarP(&currentBranch, "current-branch", "c", "", "The current branch under test")
+	watchCmd.Flags().StringVarP(&targetBranch, "target-branch", "t", diff.DefaultTargetBranch, "The branch that was compared to find the difference")
+	watchCmd.Flags().StringVarP(&hashIdsRangeParam, "hash-ids-range", "i", "", "The range of hash-ids that need to be reserved. format: 'start-hash-id,end-hash-id'")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", watch.DefaultInterval, "The interval of checking the go files for changes")
+	watchCmd.Flags().StringVar(&watchCoverMode, "covermode", "", "The cover mode passed to go test. Default: 'atomic' with -race, otherwise 'set'")
+
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(patterns, testArgs []string) error {
+	watcher, err := watch.New(patterns)
+	if err != nil {
+		return err
+	}
+	stop := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt)
+		<-signals
+		close(stop)
+	}()
+
+	coverMode := watchCoverMode
+	if coverMode == "" {
+		coverMode = defaultCoverMode(testArgs)
+	}
+	// 以包名为键保存每个包最近一次测试的覆盖率数据
+	state := make(map[string]*metadata.Package)
+	return watcher.Run(watchInterval, stop, func(pkgs, removed []string) {
+		for _, dir := range removed {
+			log.Printf("Package removed: %s\n", dir)
+			removePackage(state, dir)
+		}
+		if len(pkgs) > 0 {
+			log.Printf("Run tests: %s\n", strings.Join(pkgs, " "))
+			if err := testPackages(pkgs, coverMode, testArgs, state); err != nil {
+				log.Println(err)
+			}
+		}
+		if err := writeWatchSummary(state); err != nil {
+			log.Println(err)
+		}
+	})
+}
+
+// removePackage 删除目录 dir 中的包最近一次测试的覆盖率数据
+func removePackage(state map[string]*metadata.Package, dir string) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for name, p := range state {
+		if packageDir(p) == abs {
+			delete(state, name)
+		}
+	}
+}
+
+// packageDir 返回包的源文件所在目录，没有源文件信息时返回空字符串
+func packageDir(p *metadata.Package) string {
+	for _, s := range p.Sources {
+		return filepath.Dir(s.File)
+	}
+	for _, f := range p.Functions {
+		return filepath.Dir(f.File)
+	}
+	return ""
+}
+
+// testPackages 为变更的包运行测试，并用新的覆盖率数据更新 state
+func testPackages(pkgs []string, coverMode string, testArgs []string, state map[string]*metadata.Package) error {
+	profile, err := ioutil.TempFile("", "go-cover-*.out")
+	if err != nil {
+		return err
+	}
+	profile.Close()
+	defer os.Remove(profile.Name())
+
+	if err = runGoTest(profile.Name(), coverMode, nil, testArgs, pkgs, os.Stdout); err != nil {
+		// 测试失败时仍然使用已生成的覆盖率数据
+		log.Println(err)
+	}
+	if info, err := os.Stat(profile.Name()); err != nil || info.Size() == 0 {
+		return fmt.Errorf("no coverage profile was produced")
+	}
+
+	packages, skipped, err := convert.Do(profile.Name(), &convert.Param{KeepGoing: true})
+	if err != nil {
+		return err
+	}
+	for _, v := range skipped {
+		log.Printf("Skip file[%s]. reason: %s\n", v.File, v.Reason)
+	}
+	for _, p := range packages {
+		state[p.Name] = p
+	}
+	return nil
+}
+
+// writeWatchSummary 输出所有包中未覆盖的新代码行
+func writeWatchSummary(state map[string]*metadata.Package) error {
+	all := make(utils.Packages, 0, len(state))
+	for _, p := range state {
+		all = append(all, p)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	diffPackages, _, err := trimDiff(all)
+	if err != nil {
+		return err
+	}
+	return report.WriteUncovered(os.Stdout, diffPackages)
+}
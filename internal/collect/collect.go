@@ -0,0 +1,95 @@
+// Package collect 从运行中的程序采集覆盖率，得到 go test -coverprofile 格式的 profile
+package collect
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout 是单次请求的默认超时时间
+const DefaultTimeout = 30 * time.Second
+
+// goc 服务端的接口，见 https://github.com/qiniu/goc
+const (
+	gocProfilePath = "/v1/cover/profile"
+	gocClearPath   = "/v1/cover/clear"
+)
+
+// Source 是覆盖率的来源
+type Source interface {
+	// Fetch 将当前的覆盖率 profile 写入 w
+	Fetch(w io.Writer) error
+	// Reset 清零覆盖率计数
+	Reset() error
+}
+
+// HTTPSource 通过 HTTP 接口采集 profile
+type HTTPSource struct {
+	// ProfileURL 是以 GET 返回 profile 的地址
+	ProfileURL string
+	// ResetURL 是以 POST 清零计数的地址，为空时不支持清零
+	ResetURL string
+	// Client 是发送请求的客户端，为空时使用超时为 DefaultTimeout 的客户端
+	Client *http.Client
+}
+
+// NewGocSource 返回从 goc 服务端采集的来源，server 是服务端地址，如 http://127.0.0.1:7777
+func NewGocSource(server string) *HTTPSource {
+	server = strings.TrimRight(server, "/")
+	return &HTTPSource{ProfileURL: server + gocProfilePath, ResetURL: server + gocClearPath}
+}
+
+func (s *HTTPSource) Fetch(w io.Writer) error {
+	resp, err := s.client().Get(s.ProfileURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch coverage profile. err: %w", err)
+	}
+	defer resp.Body.Close()
+	if err = checkResponse(s.ProfileURL, resp); err != nil {
+		return err
+	}
+
+	// 确认返回的是 profile，避免把错误页面当作覆盖率写入
+	br := bufio.NewReader(resp.Body)
+	head, err := br.Peek(len("mode:"))
+	if err != nil || string(head) != "mode:" {
+		return fmt.Errorf("the response of %s is not a coverage profile", s.ProfileURL)
+	}
+	if _, err = io.Copy(w, br); err != nil {
+		return fmt.Errorf("failed to read coverage profile. err: %w", err)
+	}
+	return nil
+}
+
+func (s *HTTPSource) Reset() error {
+	if len(s.ResetURL) == 0 {
+		return fmt.Errorf("the coverage source does not support reset")
+	}
+	resp, err := s.client().Post(s.ResetURL, "application/json", bytes.NewReader([]byte("{}")))
+	if err != nil {
+		return fmt.Errorf("failed to reset coverage counters. err: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponse(s.ResetURL, resp)
+}
+
+func (s *HTTPSource) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+// checkResponse 检查响应状态码，失败时带上响应内容的开头
+func checkResponse(url string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("request %s failed. status: %s, body: %s", url, resp.Status, strings.TrimSpace(string(body)))
+}
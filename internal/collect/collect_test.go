@@ -0,0 +1,106 @@
+package collect
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const profile = "mode: count\nexample.com/calc/calc.go:5.2,5.11 1 3\n"
+
+// gocServer 模拟 goc 服务端，clear 之后 profile 中的计数清零
+func gocServer(t *testing.T) *httptest.Server {
+	count := "3"
+	mux := http.NewServeMux()
+	mux.HandleFunc(gocProfilePath, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Replace(profile, " 3\n", " "+count+"\n", 1)))
+	})
+	mux.HandleFunc(gocClearPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		count = "0"
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>not found</html>"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGocSource(t *testing.T) {
+	srv := gocServer(t)
+	s := NewGocSource(srv.URL + "/")
+
+	var buf bytes.Buffer
+	if err := s.Fetch(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != profile {
+		t.Errorf("Fetch() = %q, want %q", buf.String(), profile)
+	}
+
+	if err := s.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := s.Fetch(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), " 1 0\n") {
+		t.Errorf("Fetch() after Reset() = %q", buf.String())
+	}
+}
+
+func TestHTTPSourceErrors(t *testing.T) {
+	srv := gocServer(t)
+
+	if err := (&HTTPSource{ProfileURL: srv.URL + "/broken"}).Fetch(&bytes.Buffer{}); err == nil {
+		t.Error("Fetch() of a non-profile response should fail")
+	}
+	if err := (&HTTPSource{ProfileURL: srv.URL + "/missing"}).Fetch(&bytes.Buffer{}); err == nil {
+		t.Error("Fetch() of a 404 response should fail")
+	}
+	if err := (&HTTPSource{ProfileURL: srv.URL + gocProfilePath}).Reset(); err == nil {
+		t.Error("Reset() without ResetURL should fail")
+	}
+}
+
+func TestPoll(t *testing.T) {
+	srv := gocServer(t)
+	stop := make(chan struct{})
+	var profiles []string
+	err := Poll(NewGocSource(srv.URL), time.Millisecond, stop, func(profile string, at time.Time) error {
+		data, err := ioutil.ReadFile(profile)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, string(data))
+		if len(profiles) == 2 {
+			close(stop)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[0] != profile || profiles[1] != profile {
+		t.Errorf("Poll() fetched %q", profiles)
+	}
+
+	at := time.Date(2024, 5, 6, 18, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	if got, want := SnapshotFile("snaps", at), filepath.Join("snaps", "20240506T100000.000000000Z.json"); got != want {
+		t.Errorf("SnapshotFile() = %q, want %q", got, want)
+	}
+	// 小于一秒的间隔不会覆盖上一个快照
+	if later := SnapshotFile("snaps", at.Add(500*time.Millisecond)); later <= SnapshotFile("snaps", at) {
+		t.Errorf("SnapshotFile() of a later time = %q, want a path sorted after %q", later, SnapshotFile("snaps", at))
+	}
+}
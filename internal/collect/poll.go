@@ -0,0 +1,63 @@
+package collect
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotLayout 是快照文件名中的时间格式(UTC)，精确到纳秒以支持小于一秒的间隔，按文件名排序即按时间排序
+const snapshotLayout = "20060102T150405.000000000Z"
+
+// SnapshotFile 返回目录 dir 中 at 时刻快照的文件路径
+func SnapshotFile(dir string, at time.Time) string {
+	return filepath.Join(dir, at.UTC().Format(snapshotLayout)+".json")
+}
+
+// Poll 立即并随后每隔 interval 从 source 取一次 profile，写入临时文件后交给 fn 处理，直到 stop 被关闭。
+// 取 profile 失败只记录日志并等待下一次，fn 返回错误时结束。
+func Poll(source Source, interval time.Duration, stop <-chan struct{}, fn func(profile string, at time.Time) error) error {
+	file, err := ioutil.TempFile("", "go-cover-*.out")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	profile := file.Name()
+	defer os.Remove(profile)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		at := time.Now()
+		if err = FetchFile(source, profile); err != nil {
+			log.Println(err)
+		} else if err = fn(profile, at); err != nil {
+			return err
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// FetchFile 从来源取得 profile 并写入文件
+func FetchFile(source Source, profile string) error {
+	file, err := os.Create(profile)
+	if err != nil {
+		return err
+	}
+	if err = source.Fetch(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("failed to write coverage profile. err: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package collect
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// RuntimeSource 从 pkg/livecover 提供的接口采集，需要本机的 go tool covdata 将快照转换为 profile
+type RuntimeSource struct {
+	// URL 是 livecover.Handler 的地址，GET 返回快照，POST 清零计数
+	URL string
+	// Client 是发送请求的客户端，为空时使用超时为 DefaultTimeout 的客户端
+	Client *http.Client
+}
+
+func (s *RuntimeSource) Fetch(w io.Writer) error {
+	dir, err := ioutil.TempDir("", "go-cover-covdata-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	resp, err := s.client().Get(s.URL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch coverage snapshot. err: %w", err)
+	}
+	defer resp.Body.Close()
+	if err = checkResponse(s.URL, resp); err != nil {
+		return err
+	}
+	if err = extractArchive(resp.Body, dir); err != nil {
+		return fmt.Errorf("failed to extract coverage snapshot. err: %w", err)
+	}
+	return textfmt(dir, w)
+}
+
+func (s *RuntimeSource) Reset() error {
+	resp, err := s.client().Post(s.URL, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to reset coverage counters. err: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponse(s.URL, resp)
+}
+
+func (s *RuntimeSource) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+// extractArchive 将快照的 tar.gz 解压到目录 dir，快照中只有一层文件
+func extractArchive(r io.Reader, dir string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(h.Name)
+		if h.Typeflag != tar.TypeReg || name != h.Name {
+			return fmt.Errorf("unexpected entry in the snapshot. [%s]", h.Name)
+		}
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, tr)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// textfmt 调用 go tool covdata 将目录中的覆盖率数据转换为 profile 写入 w
+func textfmt(dir string, w io.Writer) error {
+	out := filepath.Join(dir, "profile.out")
+	cmd := exec.Command("go", "tool", "covdata", "textfmt", "-i="+dir, "-o="+out)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run go tool covdata. err: %w, output: %s", err, stderr.String())
+	}
+	file, err := os.Open(out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -0,0 +1,47 @@
+package collect
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// archive 将 name -> 内容 打包为 tar.gz
+func archive(t *testing.T, files map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractArchive(t *testing.T) {
+	dir := t.TempDir()
+	if err := extractArchive(archive(t, map[string]string{"covmeta.1": "meta", "covcounters.1.2.3": "counters"}), dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "covcounters.1.2.3"))
+	if err != nil || string(data) != "counters" {
+		t.Errorf("extracted counters = %q, %v", data, err)
+	}
+
+	if err = extractArchive(archive(t, map[string]string{"../covmeta.1": "meta"}), dir); err == nil {
+		t.Error("extractArchive() should reject paths outside the directory")
+	}
+}
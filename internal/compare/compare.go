@@ -0,0 +1,202 @@
+package compare
+
+import (
+	"sort"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// Status 是函数或包在两份数据之间的变化类型
+type Status string
+
+const (
+	StatusAdded     Status = "added"     // 只存在于新数据中
+	StatusRemoved   Status = "removed"   // 只存在于旧数据中
+	StatusChanged   Status = "changed"   // 覆盖率发生变化或有新增的未覆盖行
+	StatusUnchanged Status = "unchanged" // 覆盖情况没有变化
+)
+
+// Result 是两份覆盖率数据的对比结果
+type Result struct {
+	Metric   metadata.Metric
+	Old      metadata.Coverage
+	New      metadata.Coverage
+	Packages []*PackageDelta
+}
+
+// Delta 返回总体覆盖率百分比的变化量
+func (r *Result) Delta() float64 {
+	return r.New.Percent() - r.Old.Percent()
+}
+
+// PackageDelta 是单个包的覆盖率变化
+type PackageDelta struct {
+	Name   string
+	Status Status
+	Old    metadata.Coverage
+	New    metadata.Coverage
+	// Functions 是包中的函数变化，按文件与函数名排序
+	Functions []*FunctionDelta
+}
+
+// Delta 返回包覆盖率百分比的变化量
+func (p *PackageDelta) Delta() float64 {
+	return p.New.Percent() - p.Old.Percent()
+}
+
+// Changed 返回状态不是 StatusUnchanged 的函数
+func (p *PackageDelta) Changed() []*FunctionDelta {
+	rv := make([]*FunctionDelta, 0, len(p.Functions))
+	for _, f := range p.Functions {
+		if f.Status != StatusUnchanged {
+			rv = append(rv, f)
+		}
+	}
+	return rv
+}
+
+// FunctionDelta 是单个函数的覆盖率变化
+type FunctionDelta struct {
+	Name   string
+	File   string
+	Status Status
+	Old    metadata.Coverage
+	New    metadata.Coverage
+	// NewlyUncovered 是旧数据中已覆盖、新数据中未覆盖的行号(新数据中的行号)
+	NewlyUncovered []int
+}
+
+// Delta 返回函数覆盖率百分比的变化量
+func (f *FunctionDelta) Delta() float64 {
+	return f.New.Percent() - f.Old.Percent()
+}
+
+// Do 按包名以及函数名和文件匹配两份数据，计算每个包和函数在指定统计口径下的覆盖率变化
+func Do(old, new utils.Packages, metric metadata.Metric) *Result {
+	r := &Result{Metric: metric}
+	oldPkgs := make(map[string]*metadata.Package, len(old))
+	for _, p := range old {
+		oldPkgs[p.Name] = p
+	}
+	newPkgs := make(map[string]*metadata.Package, len(new))
+	for _, p := range new {
+		newPkgs[p.Name] = p
+	}
+
+	names := make([]string, 0, len(oldPkgs)+len(newPkgs))
+	for name := range oldPkgs {
+		names = append(names, name)
+	}
+	for name := range newPkgs {
+		if _, ok := oldPkgs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		pd := comparePackage(name, oldPkgs[name], newPkgs[name], metric)
+		r.Old.Add(pd.Old)
+		r.New.Add(pd.New)
+		r.Packages = append(r.Packages, pd)
+	}
+	return r
+}
+
+type funcKey struct {
+	name string
+	file string
+}
+
+// comparePackage 对比单个包，old 或 new 为 nil 表示包被删除或新增
+func comparePackage(name string, old, new *metadata.Package, metric metadata.Metric) *PackageDelta {
+	pd := &PackageDelta{Name: name, Status: StatusUnchanged}
+	oldFuncs := make(map[funcKey]*metadata.Function)
+	if old != nil {
+		pd.Old = old.Coverage(metric)
+		for _, f := range old.Functions {
+			oldFuncs[funcKey{f.Name, f.File}] = f
+		}
+	}
+	matched := make(map[funcKey]bool)
+	if new != nil {
+		pd.New = new.Coverage(metric)
+		for _, f := range new.Functions {
+			key := funcKey{f.Name, f.File}
+			matched[key] = true
+			pd.Functions = append(pd.Functions, compareFunction(oldFuncs[key], f, metric))
+		}
+	}
+	if old != nil {
+		for _, f := range old.Functions {
+			if !matched[funcKey{f.Name, f.File}] {
+				pd.Functions = append(pd.Functions, compareFunction(f, nil, metric))
+			}
+		}
+	}
+	sort.SliceStable(pd.Functions, func(i, j int) bool {
+		if pd.Functions[i].File != pd.Functions[j].File {
+			return pd.Functions[i].File < pd.Functions[j].File
+		}
+		return pd.Functions[i].Name < pd.Functions[j].Name
+	})
+
+	switch {
+	case old == nil:
+		pd.Status = StatusAdded
+	case new == nil:
+		pd.Status = StatusRemoved
+	case pd.Old != pd.New || len(pd.Changed()) > 0:
+		pd.Status = StatusChanged
+	}
+	return pd
+}
+
+// compareFunction 对比单个函数，old 或 new 为 nil 表示函数被删除或新增
+func compareFunction(old, new *metadata.Function, metric metadata.Metric) *FunctionDelta {
+	switch {
+	case old == nil:
+		return &FunctionDelta{Name: new.Name, File: new.File, Status: StatusAdded, New: new.Coverage(metric)}
+	case new == nil:
+		return &FunctionDelta{Name: old.Name, File: old.File, Status: StatusRemoved, Old: old.Coverage(metric)}
+	}
+	fd := &FunctionDelta{
+		Name:           new.Name,
+		File:           new.File,
+		Status:         StatusUnchanged,
+		Old:            old.Coverage(metric),
+		New:            new.Coverage(metric),
+		NewlyUncovered: newlyUncovered(old, new),
+	}
+	if fd.Old != fd.New || len(fd.NewlyUncovered) > 0 {
+		fd.Status = StatusChanged
+	}
+	return fd
+}
+
+// newlyUncovered 返回旧函数中已覆盖、新函数中未覆盖的行号。
+// 行号按相对函数起始行的偏移量对应，使整体移动位置的函数也能正确匹配。
+func newlyUncovered(old, new *metadata.Function) []int {
+	oldHits := lineHits(old)
+	var lines []int
+	for line, hit := range lineHits(new) {
+		if !hit && oldHits[line-new.StartLine+old.StartLine] {
+			lines = append(lines, line)
+		}
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// lineHits 返回函数每行是否被执行。没有记录原始覆盖块的旧数据按语句起始行统计
+func lineHits(f *metadata.Function) map[int]bool {
+	if len(f.Blocks) > 0 {
+		return f.LineHits()
+	}
+	hits := make(map[int]bool, len(f.Statements))
+	for _, s := range f.Statements {
+		hits[s.StartLine] = hits[s.StartLine] || s.Reached > 0
+	}
+	return hits
+}
@@ -0,0 +1,59 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// function 创建一个函数，counts 依次是从 startLine 开始每行一个覆盖块的执行次数
+func function(name string, startLine int, counts ...int64) *metadata.Function {
+	f := &metadata.Function{Name: name, File: "a.go", StartLine: startLine}
+	for i, c := range counts {
+		line := startLine + 1 + i
+		f.Statements = append(f.Statements, &metadata.Statement{StartLine: line, EndLine: line, Reached: c})
+		f.Blocks = append(f.Blocks, &metadata.Block{StartLine: line, StartCol: 2, EndLine: line, EndCol: 10, NumStmt: 1, Count: c})
+	}
+	return f
+}
+
+func TestDo(t *testing.T) {
+	old := utils.Packages{
+		{Name: "a", Functions: []*metadata.Function{
+			function("Moved", 10, 1, 1, 0),
+			function("Removed", 20, 1),
+			function("Same", 30, 1, 0),
+		}},
+		{Name: "gone", Functions: []*metadata.Function{function("F", 1, 1)}},
+	}
+	new := utils.Packages{
+		{Name: "a", Functions: []*metadata.Function{
+			// 函数整体下移 5 行，第 2 行不再被覆盖
+			function("Moved", 15, 1, 0, 0),
+			function("Added", 40, 0),
+			function("Same", 30, 1, 0),
+		}},
+	}
+
+	r := Do(old, new, metadata.MetricStatement)
+	if len(r.Packages) != 2 || r.Packages[0].Name != "a" || r.Packages[1].Status != StatusRemoved {
+		t.Fatalf("Do() packages = %+v, want a and removed gone", r.Packages)
+	}
+	want := map[string]Status{"Moved": StatusChanged, "Removed": StatusRemoved, "Added": StatusAdded, "Same": StatusUnchanged}
+	for _, f := range r.Packages[0].Functions {
+		if f.Status != want[f.Name] {
+			t.Errorf("%s status = %s, want %s", f.Name, f.Status, want[f.Name])
+		}
+		if f.Name == "Moved" && !reflect.DeepEqual(f.NewlyUncovered, []int{17}) {
+			t.Errorf("Moved newly uncovered lines = %v, want [17]", f.NewlyUncovered)
+		}
+	}
+	if len(r.Packages[0].Changed()) != 3 {
+		t.Errorf("Changed() = %d functions, want 3", len(r.Packages[0].Changed()))
+	}
+	if r.Old != (metadata.Coverage{Reached: 5, Total: 7}) || r.New != (metadata.Coverage{Reached: 2, Total: 6}) {
+		t.Errorf("Do() total = %+v -> %+v, want 5/7 -> 2/6", r.Old, r.New)
+	}
+}
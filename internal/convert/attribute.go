@@ -0,0 +1,77 @@
+package convert
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/lamber92/go-cover/internal/errs"
+	"github.com/lamber92/go-cover/internal/utils"
+	"golang.org/x/tools/cover"
+)
+
+// blockKey 按 profile 中的文件名与位置标识一个覆盖块
+type blockKey struct {
+	file                                 string
+	startLine, startCol, endLine, endCol int
+}
+
+// Attribute 读取只运行了单个测试的 profile，将测试登记到 ps 中被它执行过的覆盖块上。
+// profile 中的文件名是 包导入路径/文件名，据此与 ps 中的包和函数对应。
+func Attribute(ps utils.Packages, test, profile string) error {
+	profiles, err := cover.ParseProfiles(profile)
+	if err != nil {
+		return &errs.ParseError{File: profile, Err: err}
+	}
+	hits := make(map[blockKey]struct{})
+	for _, p := range profiles {
+		for _, b := range p.Blocks {
+			if b.Count > 0 {
+				hits[blockKey{p.FileName, b.StartLine, b.StartCol, b.EndLine, b.EndCol}] = struct{}{}
+			}
+		}
+	}
+	if len(hits) == 0 {
+		return nil
+	}
+
+	for _, pkg := range ps {
+		for _, f := range pkg.Functions {
+			file := pkg.Name + "/" + filepath.Base(f.File)
+			for _, b := range f.Blocks {
+				if _, ok := hits[blockKey{file, b.StartLine, b.StartCol, b.EndLine, b.EndCol}]; ok {
+					b.AddTest(test)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// AttributeTests 逐个运行测试，将每个测试登记到 ps 中被它执行过的覆盖块上，返回没有产生 profile 的测试。
+// run 运行单个测试并将覆盖率数据写入 profile，测试失败时仍会登记已写入的数据；
+// 每次运行前都会删除 profile，编译失败等没有写入 profile 的测试不会沿用上一个测试的数据。
+func AttributeTests(ps utils.Packages, tests []string, run func(test, profile string)) (skipped []string, err error) {
+	file, err := ioutil.TempFile("", "go-cover-test-*.out")
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	profile := file.Name()
+	defer os.Remove(profile)
+
+	for _, t := range tests {
+		if err = os.Remove(profile); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		run(t, profile)
+		if info, err := os.Stat(profile); err != nil || info.Size() == 0 {
+			skipped = append(skipped, t)
+			continue
+		}
+		if err = Attribute(ps, t, profile); err != nil {
+			return nil, err
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,114 @@
+package convert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+)
+
+// writeTestProfile 根据合成 profile 生成只运行了一个测试的 set 模式 profile，hit 中的函数的所有块都被执行过
+func writeTestProfile(t *testing.T, full string, hit ...int) string {
+	t.Helper()
+	data, err := ioutil.ReadFile(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")[1:]
+	var b strings.Builder
+	b.WriteString("mode: set\n")
+	for i, line := range lines {
+		count := 0
+		for _, j := range hit {
+			// 合成 profile 中每个函数 3 个块
+			if i/3 == j {
+				count = 1
+			}
+		}
+		fmt.Fprintf(&b, "%s %d\n", line[:strings.LastIndex(line, " ")], count)
+	}
+	path := filepath.Join(t.TempDir(), "test.out")
+	if err = ioutil.WriteFile(path, []byte(b.String()), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAttribute(t *testing.T) {
+	full := writeSyntheticProfile(t, 1, 3)
+	ps, _, err := Do(full, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test1 := metadata.TestID("example.com/p", "TestOne")
+	test2 := metadata.TestID("example.com/p", "TestTwo")
+	if err = Attribute(ps, test1, writeTestProfile(t, full, 0, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err = Attribute(ps, test2, writeTestProfile(t, full, 2)); err != nil {
+		t.Fatal(err)
+	}
+	// 同一测试重复归因不会重复登记
+	if err = Attribute(ps, test2, writeTestProfile(t, full, 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{test1}, nil, {test1, test2}}
+	for i, f := range ps[0].Functions {
+		if got := f.Tests(); strings.Join(got, ",") != strings.Join(want[i], ",") {
+			t.Errorf("%s tests = %v, want %v", f.Name, got, want[i])
+		}
+		for _, b := range f.Blocks {
+			if strings.Join(b.Tests, ",") != strings.Join(want[i], ",") {
+				t.Errorf("%s block %d.%d tests = %v, want %v", f.Name, b.StartLine, b.StartCol, b.Tests, want[i])
+			}
+		}
+	}
+}
+
+func TestAttributeTests(t *testing.T) {
+	full := writeSyntheticProfile(t, 1, 3)
+	ps, _, err := Do(full, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testA := metadata.TestID("example.com/p", "TestA")
+	testB := metadata.TestID("example.com/p", "TestB")
+	testC := metadata.TestID("example.com/p", "TestC")
+	profiles := map[string]string{
+		testA: writeTestProfile(t, full, 0),
+		testC: writeTestProfile(t, full, 2),
+	}
+	skipped, err := AttributeTests(ps, []string{testA, testB, testC}, func(test, profile string) {
+		// TestB 编译失败，没有写入 profile
+		src, ok := profiles[test]
+		if !ok {
+			return
+		}
+		data, err := ioutil.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(profile, data, 0666); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(skipped, ",") != testB {
+		t.Errorf("skipped = %v, want [%s]", skipped, testB)
+	}
+
+	want := [][]string{{testA}, nil, {testC}}
+	for i, f := range ps[0].Functions {
+		for _, b := range f.Blocks {
+			if strings.Join(b.Tests, ",") != strings.Join(want[i], ",") {
+				t.Errorf("%s block %d.%d tests = %v, want %v", f.Name, b.StartLine, b.StartCol, b.Tests, want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package convert
+
+import (
+	"sort"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"golang.org/x/tools/cover"
+)
+
+// buildBranches 根据 profile 块计算函数中各判定点的分支执行次数。
+// set 模式下计数只有 0/1，隐含分支只在判定点被执行且其余分支都未执行时才能确定被执行。
+func buildBranches(decisions []*decisionExtent, blocks []cover.ProfileBlock, mode string) []*metadata.Branch {
+	branches := make([]*metadata.Branch, 0, len(decisions))
+	for _, d := range decisions {
+		branch := &metadata.Branch{Kind: d.kind, Line: d.pos.line}
+		var explicit int64
+		for _, oe := range d.outcomes {
+			if oe.implicit {
+				continue
+			}
+			count := firstBlockCount(blocks, oe.start, oe.end)
+			explicit += count
+			branch.Outcomes = append(branch.Outcomes, &metadata.Outcome{Label: oe.label, Line: oe.start.line, Count: count})
+		}
+		for _, oe := range d.outcomes {
+			if !oe.implicit {
+				continue
+			}
+			var count int64
+			evaluated := containingBlockCount(blocks, d.pos)
+			if mode == metadata.ModeSet {
+				if evaluated > 0 && explicit == 0 {
+					count = 1
+				}
+			} else if evaluated > explicit {
+				count = evaluated - explicit
+			}
+			branch.Outcomes = append(branch.Outcomes, &metadata.Outcome{Label: oe.label, Line: oe.start.line, Count: count})
+		}
+		branches = append(branches, branch)
+	}
+	return branches
+}
+
+// firstBlockCount 返回起始位置落在 [start, end) 内的第一个块的执行次数，即分支体被执行的次数
+func firstBlockCount(blocks []cover.ProfileBlock, start, end position) int64 {
+	i := sort.Search(len(blocks), func(i int) bool {
+		return !(position{blocks[i].StartLine, blocks[i].StartCol}).before(start)
+	})
+	if i < len(blocks) && (position{blocks[i].StartLine, blocks[i].StartCol}).before(end) {
+		return int64(blocks[i].Count)
+	}
+	return 0
+}
+
+// containingBlockCount 返回包含指定位置的块的执行次数，即判定点被执行的次数
+func containingBlockCount(blocks []cover.ProfileBlock, pos position) int64 {
+	i := sort.Search(len(blocks), func(i int) bool {
+		return pos.before(position{blocks[i].StartLine, blocks[i].StartCol})
+	})
+	if i > 0 && !(position{blocks[i-1].EndLine, blocks[i-1].EndCol}).before(pos) {
+		return int64(blocks[i-1].Count)
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package convert
+
+import (
+	"go/build"
+	"io/ioutil"
+	"sync"
+
+	"github.com/lamber92/go-cover/internal/errs"
+)
+
+// packagesCache 缓存目录对应的包信息，可并发使用
+type packagesCache struct {
+	mu      sync.Mutex
+	entries map[string]*packageEntry
+}
+
+type packageEntry struct {
+	once sync.Once
+	pkg  *build.Package
+	err  error
+}
+
+func newPackagesCache() *packagesCache {
+	return &packagesCache{entries: make(map[string]*packageEntry)}
+}
+
+// get 获取目录对应的包信息，同一目录只查找一次
+func (c *packagesCache) get(dir string) (*build.Package, error) {
+	c.mu.Lock()
+	e, ok := c.entries[dir]
+	if !ok {
+		e = &packageEntry{}
+		c.entries[dir] = e
+	}
+	c.mu.Unlock()
+
+	e.once.Do(func() {
+		e.pkg, e.err = build.Import(dir, ".", build.FindOnly)
+	})
+	return e.pkg, e.err
+}
+
+// filesCache 缓存已解析的源文件，可并发使用。
+// ParseProfiles 已按 profile 中的文件名合并记录，这里以解析后的完整路径为键，
+// 使同一源文件以不同文件名(如导入路径与 ./ 开头的相对路径)出现时也只读取、解析一次。
+type filesCache struct {
+	mu      sync.Mutex
+	entries map[string]*fileEntry
+}
+
+type fileEntry struct {
+	once    sync.Once
+	src     []byte
+	extents []*FuncExtent
+	err     error
+}
+
+func newFilesCache() *filesCache {
+	return &filesCache{entries: make(map[string]*fileEntry)}
+}
+
+// get 读取并解析源文件，filename 须为完整路径，同一文件只解析一次
+func (c *filesCache) get(filename string) (src []byte, extents []*FuncExtent, err error) {
+	c.mu.Lock()
+	e, ok := c.entries[filename]
+	if !ok {
+		e = &fileEntry{}
+		c.entries[filename] = e
+	}
+	c.mu.Unlock()
+
+	e.once.Do(func() {
+		if e.src, e.err = ioutil.ReadFile(filename); e.err != nil {
+			e.err = &errs.MissingSourceError{File: filename, Err: e.err}
+			return
+		}
+		e.extents, e.err = findFuncs(filename, e.src)
+	})
+	return e.src, e.extents, e.err
+}
@@ -0,0 +1,152 @@
+package convert
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// cyclomaticComplexity 计算函数体的圈复杂度：1 + 判定点数量。
+// 判定点包括 if、for、range、非 default 的 case 与通信分支以及 && 和 ||。
+// 函数字面量作为独立的函数统计，不计入外层函数。
+func cyclomaticComplexity(body *ast.BlockStmt) int {
+	complexity := 1
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// cognitiveComplexity 计算函数体的认知复杂度。
+// 控制结构按嵌套深度额外计分，else if / else 与带标签的跳转各计 1 分，
+// 连续相同的逻辑运算符只计 1 分。函数字面量作为独立的函数统计，不计入外层函数。
+func cognitiveComplexity(body *ast.BlockStmt) int {
+	c := &cognitive{}
+	c.visit(body, 0)
+	return c.complexity
+}
+
+type cognitive struct {
+	complexity int
+}
+
+func (c *cognitive) visit(node ast.Node, nesting int) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.IfStmt:
+			c.complexity += 1 + nesting
+			c.visitIf(s, nesting)
+			return false
+		case *ast.ForStmt:
+			c.complexity += 1 + nesting
+			c.visitAll(nesting, s.Init, s.Cond, s.Post)
+			c.visit(s.Body, nesting+1)
+			return false
+		case *ast.RangeStmt:
+			c.complexity += 1 + nesting
+			c.visitAll(nesting, s.X)
+			c.visit(s.Body, nesting+1)
+			return false
+		case *ast.SwitchStmt:
+			c.complexity += 1 + nesting
+			c.visitAll(nesting, s.Init, s.Tag)
+			c.visit(s.Body, nesting+1)
+			return false
+		case *ast.TypeSwitchStmt:
+			c.complexity += 1 + nesting
+			c.visitAll(nesting, s.Init, s.Assign)
+			c.visit(s.Body, nesting+1)
+			return false
+		case *ast.SelectStmt:
+			c.complexity += 1 + nesting
+			c.visit(s.Body, nesting+1)
+			return false
+		case *ast.BranchStmt:
+			if s.Label != nil {
+				c.complexity++
+			}
+		case *ast.BinaryExpr:
+			if s.Op == token.LAND || s.Op == token.LOR {
+				c.complexity += logicalSequences(s, token.ILLEGAL)
+				c.visitOperands(s, nesting)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// visitIf 处理 if 语句的条件、分支体以及 else if / else 链
+func (c *cognitive) visitIf(s *ast.IfStmt, nesting int) {
+	c.visitAll(nesting, s.Init, s.Cond)
+	c.visit(s.Body, nesting+1)
+	switch e := s.Else.(type) {
+	case *ast.IfStmt:
+		c.complexity++
+		c.visitIf(e, nesting)
+	case *ast.BlockStmt:
+		c.complexity++
+		c.visit(e, nesting+1)
+	}
+}
+
+func (c *cognitive) visitAll(nesting int, nodes ...ast.Node) {
+	for _, n := range nodes {
+		if n != nil {
+			c.visit(n, nesting)
+		}
+	}
+}
+
+// visitOperands 继续访问逻辑表达式中非逻辑运算的操作数(其中可能包含函数调用等)
+func (c *cognitive) visitOperands(e ast.Expr, nesting int) {
+	if b, ok := unparen(e).(*ast.BinaryExpr); ok && (b.Op == token.LAND || b.Op == token.LOR) {
+		c.visitOperands(b.X, nesting)
+		c.visitOperands(b.Y, nesting)
+		return
+	}
+	c.visit(e, nesting)
+}
+
+// logicalSequences 统计逻辑表达式中相同运算符的连续序列数量。
+// 如 a && b && c 计 1，a && b || c 计 2。
+func logicalSequences(e ast.Expr, parent token.Token) int {
+	b, ok := unparen(e).(*ast.BinaryExpr)
+	if !ok || (b.Op != token.LAND && b.Op != token.LOR) {
+		return 0
+	}
+	n := 0
+	if b.Op != parent {
+		n = 1
+	}
+	return n + logicalSequences(b.X, b.Op) + logicalSequences(b.Y, b.Op)
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
@@ -0,0 +1,40 @@
+package convert
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestComplexity(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		cyclomatic int
+		cognitive  int
+	}{
+		{"empty", "", 1, 0},
+		{"if", "if x > 0 { return }", 2, 1},
+		{"else if chain", "if x > 0 { return } else if x < 0 { return } else { return }", 3, 3},
+		{"logical", "if x > 0 && x < 10 || x == 20 { return }", 4, 3},
+		{"nested", "for i := 0; i < x; i++ { if i > 2 { break } }", 3, 3},
+		{"switch", "switch x { case 1, 2: return; case 3: return; default: return }", 3, 1},
+		{"labeled", "L: for { select { case <-ch: continue L; default: } }", 3, 4},
+		{"func literal", "f := func() { if x > 0 { return } }; f()", 1, 0},
+	}
+	for _, tt := range tests {
+		src := "package p\nfunc f(x int, ch chan int) {\n" + tt.body + "\n}\n"
+		file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		body := file.Decls[0].(*ast.FuncDecl).Body
+		if got := cyclomaticComplexity(body); got != tt.cyclomatic {
+			t.Errorf("%s: cyclomaticComplexity() = %d, want %d", tt.name, got, tt.cyclomatic)
+		}
+		if got := cognitiveComplexity(body); got != tt.cognitive {
+			t.Errorf("%s: cognitiveComplexity() = %d, want %d", tt.name, got, tt.cognitive)
+		}
+	}
+}
@@ -1,31 +1,150 @@
 package convert
 
 import (
-	"go/build"
+	"errors"
+	"runtime"
+	"sync"
 
+	"github.com/lamber92/go-cover/internal/errs"
 	"github.com/lamber92/go-cover/internal/metadata"
 	"github.com/lamber92/go-cover/internal/utils"
 	"golang.org/x/tools/cover"
 )
 
-type packagesCache map[string]*build.Package
+// Param 转换参数
+type Param struct {
+	// EmbedSource 是否将源文件内容(压缩后)嵌入到转换结果中
+	EmbedSource bool
+	// KeepGoing 是否跳过找不到或无法解析的源文件继续转换
+	KeepGoing bool
+	// Workers 是并发转换的协程数。小于等于 0 时使用 CPU 核数
+	Workers int
+}
 
-func Do(filename string) (ps utils.Packages, err error) {
+// Do 加载并转换 Go-Coverage-Profile 文件。param 为 nil 时使用默认参数。
+// 开启 KeepGoing 时，找不到或无法解析的源文件会被跳过并记录在 skipped 中。
+// 各文件并发转换，结果与顺序转换一致。
+func Do(filename string, param *Param) (ps utils.Packages, skipped []*errs.SkippedFile, err error) {
+	if param == nil {
+		param = &Param{}
+	}
 	profiles, err := cover.ParseProfiles(filename)
 	if err != nil {
+		err = &errs.ParseError{File: filename, Err: err}
 		return
 	}
+	conv := &converter{
+		embedSource: param.EmbedSource,
+		packages:    newPackagesCache(),
+		files:       newFilesCache(),
+	}
+	// 记录当前提交点，便于渲染时在源文件变更后取回原始内容；不在 git 仓库中时忽略
+	conv.commit, _ = utils.GetHeadCommit()
+
+	results, errList := convertProfiles(conv, profiles, param.Workers)
+
+	// 按 profile 顺序汇总结果，保证输出稳定
 	var (
-		packages = make(packagesCache)
-		conv     = converter{packages: make(map[string]*metadata.Package)}
+		packages  = make(map[string]*metadata.Package)
+		names     []string
+		functions = make(map[functionKey]*metadata.Function)
 	)
-	for _, p := range profiles {
-		if err = conv.convertProfile(packages, p); err != nil {
+	for i, p := range profiles {
+		if errList[i] != nil {
+			if !param.KeepGoing || !skippable(errList[i]) {
+				err = errList[i]
+				return
+			}
+			skipped = append(skipped, errs.NewSkippedFile(p.FileName, errList[i]))
+			continue
+		}
+		result := results[i]
+		var fresh []*metadata.Function
+		if fresh, err = dedupeFunctions(functions, result.functions, p.Mode); err != nil {
 			return
 		}
+		if len(fresh) == 0 {
+			continue
+		}
+		pkg := packages[result.pkgPath]
+		if pkg == nil {
+			pkg = &metadata.Package{Name: result.pkgPath, Mode: p.Mode}
+			packages[result.pkgPath] = pkg
+			names = append(names, result.pkgPath)
+		}
+		pkg.AddSource(result.source)
+		pkg.Functions = append(pkg.Functions, fresh...)
 	}
-	for _, pkg := range conv.packages {
-		ps.AppendPackage(pkg)
+	for _, name := range names {
+		if err = ps.AppendPackage(packages[name]); err != nil {
+			return
+		}
 	}
 	return
 }
+
+// functionKey 以源文件和函数范围标识一个函数
+type functionKey struct {
+	file       string
+	start, end int
+}
+
+// dedupeFunctions 返回 fs 中尚未转换过的函数，并把已转换过的函数的覆盖率按 mode 合并到先前的结果中。
+// go test -coverpkg 运行多个测试程序时，同一源文件可能以不同的文件名(如导入路径与相对路径)出现在 profile 中，
+// ParseProfiles 只会合并文件名相同的记录，这里按解析后的完整路径去重。
+func dedupeFunctions(seen map[functionKey]*metadata.Function, fs []*metadata.Function, mode string) ([]*metadata.Function, error) {
+	fresh := fs[:0:0]
+	for _, f := range fs {
+		key := functionKey{file: f.File, start: f.Start, end: f.End}
+		if existing := seen[key]; existing != nil {
+			if err := existing.Merge(f, mode); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		seen[key] = f
+		fresh = append(fresh, f)
+	}
+	return fresh, nil
+}
+
+// convertProfiles 使用有限数量的协程并发转换 profile，结果与 profiles 一一对应
+func convertProfiles(conv *converter, profiles []*cover.Profile, workers int) ([]*fileResult, []error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(profiles) {
+		workers = len(profiles)
+	}
+
+	var (
+		results = make([]*fileResult, len(profiles))
+		errList = make([]error, len(profiles))
+		jobs    = make(chan int)
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errList[i] = conv.convertProfile(profiles[i])
+			}
+		}()
+	}
+	for i := range profiles {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results, errList
+}
+
+// skippable 判断转换错误是否可以在“继续执行”模式下跳过
+func skippable(err error) bool {
+	var (
+		missingErr *errs.MissingSourceError
+		parseErr   *errs.ParseError
+	)
+	return errors.As(err, &missingErr) || errors.As(err, &parseErr)
+}
@@ -0,0 +1,223 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+	"golang.org/x/tools/cover"
+)
+
+// writeSyntheticProfile 生成包含 numFiles 个文件、每个文件 numFuncs 个函数的合成 profile，返回 profile 路径。
+// 源文件写在临时目录中，并以相对当前目录的本地导入路径记录在 profile 里。
+func writeSyntheticProfile(tb testing.TB, numFiles, numFuncs int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err = os.Mkdir(filepath.Join(dir, "pkg"), 0777); err != nil {
+		tb.Fatal(err)
+	}
+
+	var profile bytes.Buffer
+	profile.WriteString("mode: count\n")
+	for i := 0; i < numFiles; i++ {
+		name := fmt.Sprintf("file%04d.go", i)
+		var src bytes.Buffer
+		src.WriteString("package pkg\n")
+		for j := 0; j < numFuncs; j++ {
+			// 每个函数占 7 行：空行、签名、if、return、}、return、}
+			line := 2 + j*7 + 1
+			fmt.Fprintf(&src, "\nfunc F%d_%d(x int) int {\n\tif x > %d {\n\t\treturn x\n\t}\n\treturn -x\n}\n", i, j, j)
+			path := filepath.ToSlash(filepath.Join(rel, "pkg", name))
+			fmt.Fprintf(&profile, "%s:%d.2,%d.12 1 %d\n", path, line+1, line+1, j%3)
+			fmt.Fprintf(&profile, "%s:%d.12,%d.11 1 %d\n", path, line+1, line+3, j%2)
+			fmt.Fprintf(&profile, "%s:%d.2,%d.11 1 %d\n", path, line+4, line+4, j%5)
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, "pkg", name), src.Bytes(), 0666); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "coverage.out")
+	if err = ioutil.WriteFile(path, profile.Bytes(), 0666); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+func TestDoDeterministic(t *testing.T) {
+	profile := writeSyntheticProfile(t, 20, 10)
+
+	var outputs [][]byte
+	for _, workers := range []int{1, 8} {
+		ps, _, err := Do(profile, &Param{Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err = utils.MarshalJson(&buf, ps); err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, buf.Bytes())
+	}
+	if !bytes.Equal(outputs[0], outputs[1]) {
+		t.Error("Do() output differs between sequential and parallel conversion")
+	}
+}
+
+func TestDoReached(t *testing.T) {
+	profile := writeSyntheticProfile(t, 1, 3)
+	ps, _, err := Do(profile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || len(ps[0].Functions) != 3 {
+		t.Fatalf("Do() = %+v, want 1 package with 3 functions", ps)
+	}
+	// 语句依次为 if、return x、return -x，分别命中 3 个块
+	want := [][]int64{{0, 0, 0}, {1, 1, 1}, {2, 0, 2}}
+	// 每个函数只有一个 if，分支依次为 then 与隐式 else
+	wantBranches := [][]int64{{0, 0}, {1, 0}, {0, 2}}
+	for i, f := range ps[0].Functions {
+		if len(f.Branches) != 1 || len(f.Branches[0].Outcomes) != 2 {
+			t.Fatalf("%s branches = %+v, want 1 if with 2 outcomes", f.Name, f.Branches)
+		}
+		for j, o := range f.Branches[0].Outcomes {
+			if o.Count != wantBranches[i][j] {
+				t.Errorf("%s branch %s count = %d, want %d", f.Name, o.Label, o.Count, wantBranches[i][j])
+			}
+		}
+		if len(f.Blocks) != 3 {
+			t.Errorf("%s blocks = %d, want 3", f.Name, len(f.Blocks))
+		}
+		for j, s := range f.Statements {
+			if s.Reached != want[i][j] {
+				t.Errorf("%s statement %d reached = %d, want %d", f.Name, j, s.Reached, want[i][j])
+			}
+		}
+	}
+}
+
+// writeDuplicatedProfile 将合成 profile 的每条记录以 "./" 开头的文件名再写一遍，
+// 模拟 -coverpkg 下多个测试程序以不同文件名记录同一源文件的情况。set 模式下执行次数记为 0 或 1。
+func writeDuplicatedProfile(t *testing.T, mode string) string {
+	t.Helper()
+	path := writeSyntheticProfile(t, 1, 3)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")[1:]
+	var profile bytes.Buffer
+	fmt.Fprintf(&profile, "mode: %s\n", mode)
+	for _, prefix := range []string{"", "./"} {
+		for _, line := range lines {
+			if mode == metadata.ModeSet && !strings.HasSuffix(line, " 0") {
+				line = line[:strings.LastIndex(line, " ")] + " 1"
+			}
+			fmt.Fprintf(&profile, "%s%s\n", prefix, line)
+		}
+	}
+	if err = ioutil.WriteFile(path, profile.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDoDuplicatedFiles(t *testing.T) {
+	tests := []struct {
+		mode string
+		want [][]int64 // 各函数语句的执行次数
+	}{
+		{mode: metadata.ModeCount, want: [][]int64{{0, 0, 0}, {2, 2, 2}, {4, 0, 4}}},
+		{mode: metadata.ModeSet, want: [][]int64{{0, 0, 0}, {1, 1, 1}, {1, 0, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			ps, _, err := Do(writeDuplicatedProfile(t, tt.mode), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ps) != 1 || len(ps[0].Functions) != 3 {
+				t.Fatalf("Do() = %+v, want 1 package with 3 functions", ps)
+			}
+			if len(ps[0].Sources) != 1 {
+				t.Errorf("sources = %d, want 1", len(ps[0].Sources))
+			}
+			for i, f := range ps[0].Functions {
+				if len(f.Blocks) != 3 {
+					t.Errorf("%s blocks = %d, want 3", f.Name, len(f.Blocks))
+				}
+				for j, s := range f.Statements {
+					if s.Reached != tt.want[i][j] {
+						t.Errorf("%s statement %d reached = %d, want %d", f.Name, j, s.Reached, tt.want[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestFilesCacheHit 同一源文件以两个文件名出现在 profile 中时只解析一次
+func TestFilesCacheHit(t *testing.T) {
+	profiles, err := cover.ParseProfiles(writeDuplicatedProfile(t, metadata.ModeCount))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("ParseProfiles() = %d profiles, want 2", len(profiles))
+	}
+	conv := &converter{packages: newPackagesCache(), files: newFilesCache()}
+	_, errList := convertProfiles(conv, profiles, 2)
+	for _, err := range errList {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(conv.packages.entries); got != 2 {
+		t.Errorf("packages cache = %d entries, want 2", got)
+	}
+	if got := len(conv.files.entries); got != 1 {
+		t.Errorf("files cache = %d entries, want 1", got)
+	}
+}
+
+func benchmarkDo(b *testing.B, numFiles, numFuncs int) {
+	profile := writeSyntheticProfile(b, numFiles, numFuncs)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := Do(profile, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDo100Files(b *testing.B) {
+	benchmarkDo(b, 100, 20)
+}
+
+func BenchmarkDo1000Files(b *testing.B) {
+	benchmarkDo(b, 1000, 20)
+}
+
+func BenchmarkDo1000FilesSequential(b *testing.B) {
+	profile := writeSyntheticProfile(b, 1000, 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := Do(profile, &Param{Workers: 1}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,20 +1,32 @@
 package convert
 
 import (
-	"fmt"
 	"go/ast"
-	"go/build"
 	"go/parser"
 	"go/token"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/lamber92/go-cover/internal/errs"
 	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
 	"golang.org/x/tools/cover"
 )
 
+// converter 转换 profile 文件内容，可被多个协程并发使用
 type converter struct {
-	packages map[string]*metadata.Package
+	commit      string
+	embedSource bool
+	packages    *packagesCache
+	files       *filesCache
+}
+
+// fileResult 是单个 profile 文件的转换结果
+type fileResult struct {
+	pkgPath   string
+	source    *metadata.Source
+	functions []*metadata.Function
 }
 
 // statement metadata.Statement 的包装器
@@ -24,32 +36,45 @@ type statement struct {
 }
 
 // convertProfile 转换 profile 文件内容
-func (c *converter) convertProfile(packages packagesCache, p *cover.Profile) error {
-	file, pkgPath, err := c.findFile(packages, p.FileName)
+func (c *converter) convertProfile(p *cover.Profile) (*fileResult, error) {
+	file, pkgPath, err := c.findFile(p.FileName)
 	if err != nil {
-		return err
-	}
-	pkg := c.packages[pkgPath]
-	if pkg == nil {
-		pkg = &metadata.Package{Name: pkgPath}
-		c.packages[pkgPath] = pkg
+		return nil, err
 	}
 
 	// 查找函数和语句范围；创建相应的 convert.Functions 和 convert.Statements，
 	// 并保留一个单独的 convert.Statements 片段，以便将它们与 profile 匹配。
-	extents, err := c.findFuncs(file)
+	src, extents, err := c.files.get(file)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// 记录源文件摘要，用于渲染时判断源文件是否已变更
+	result := &fileResult{
+		pkgPath: pkgPath,
+		source: &metadata.Source{
+			File:   file,
+			Hash:   utils.HashContent(src),
+			Commit: c.commit,
+		},
+	}
+	if c.embedSource {
+		if result.source.Content, err = utils.Compress(src); err != nil {
+			return nil, err
+		}
 	}
+
 	var stmts []statement
 	for _, fe := range extents {
 		f := &metadata.Function{
-			Name:      fe.name,
-			File:      file,
-			Start:     fe.startOffset,
-			End:       fe.endOffset,
-			StartLine: fe.startLine,
-			EndLine:   fe.endLine,
+			Name:       fe.name,
+			File:       file,
+			Start:      fe.startOffset,
+			End:        fe.endOffset,
+			StartLine:  fe.startLine,
+			EndLine:    fe.endLine,
+			Cyclomatic: fe.cyclomatic,
+			Cognitive:  fe.cognitive,
 		}
 		for _, se := range fe.stmts {
 			s := statement{
@@ -64,54 +89,88 @@ func (c *converter) convertProfile(packages packagesCache, p *cover.Profile) err
 			f.Statements = append(f.Statements, s.Statement)
 			stmts = append(stmts, s)
 		}
-		pkg.Functions = append(pkg.Functions, f)
+		f.Branches = buildBranches(fe.decisions, p.Blocks, p.Mode)
+		result.functions = append(result.functions, f)
+	}
+	// 保留原始覆盖块，归入包含其起始位置的最内层函数
+	for _, b := range p.Blocks {
+		if i := innermostFunc(extents, b.StartLine, b.StartCol); i >= 0 {
+			f := result.functions[i]
+			f.Blocks = append(f.Blocks, &metadata.Block{
+				StartLine: b.StartLine,
+				StartCol:  b.StartCol,
+				EndLine:   b.EndLine,
+				EndCol:    b.EndCol,
+				NumStmt:   b.NumStmt,
+				Count:     int64(b.Count),
+			})
+		}
 	}
-	// 对于文件中的每个配置文件块，找到它涵盖的语句并递增 Reached 字段。
+	// 对于文件中的每个语句，找到覆盖它的第一个配置文件块并递增 Reached 字段。
 	blocks := p.Blocks
 	for _, s := range stmts {
-		for i, b := range blocks {
-			if b.StartLine > s.endLine || (b.StartLine == s.endLine && b.StartCol >= s.endCol) {
-				// 超过语句末尾
-				blocks = blocks[i:]
-				break
-			}
-			if b.EndLine < s.startLine || (b.EndLine == s.startLine && b.EndCol <= s.startCol) {
-				// 在语句开始之前
-				continue
-			}
-			s.Reached += int64(b.Count)
-			break
+		// 块按位置排序且互不重叠，二分查找第一个不在语句开始之前结束的块
+		i := sort.Search(len(blocks), func(i int) bool {
+			b := blocks[i]
+			return b.EndLine > s.startLine || (b.EndLine == s.startLine && b.EndCol > s.startCol)
+		})
+		if i == len(blocks) {
+			continue
+		}
+		if b := blocks[i]; b.StartLine > s.endLine || (b.StartLine == s.endLine && b.StartCol >= s.endCol) {
+			// 超过语句末尾
+			continue
+		}
+		s.Reached += int64(blocks[i].Count)
+	}
+	return result, nil
+}
+
+// innermostFunc 返回包含指定位置的最内层函数的下标，没有则返回 -1。
+// extents 按起始位置排序，嵌套的函数字面量排在外层函数之后。
+func innermostFunc(extents []*FuncExtent, line, col int) int {
+	i := sort.Search(len(extents), func(i int) bool {
+		e := extents[i]
+		return e.startLine > line || (e.startLine == line && e.startCol > col)
+	})
+	for i--; i >= 0; i-- {
+		e := extents[i]
+		if e.endLine > line || (e.endLine == line && e.endCol >= col) {
+			return i
 		}
 	}
-	return nil
+	return -1
 }
 
 // findFile 在 GOROOT、GOPATH 等中查找命名文件的位置。
-func (c *converter) findFile(packages packagesCache, file string) (filename, pkgPath string, err error) {
-	dir, file := filepath.Split(file)
+func (c *converter) findFile(path string) (filename, pkgPath string, err error) {
+	dir, file := filepath.Split(path)
 	if dir != "" {
 		dir = strings.TrimSuffix(dir, "/")
 	}
-	pkg, ok := packages[dir]
-	if !ok {
-		pkg, err = build.Import(dir, ".", build.FindOnly)
-		if err != nil {
-			return "", "", fmt.Errorf("can't find %q: %w", file, err)
-		}
-		packages[dir] = pkg
+	pkg, err := c.packages.get(dir)
+	if err != nil {
+		return "", "", &errs.MissingSourceError{File: path, Err: err}
 	}
 
-	return filepath.Join(pkg.Dir, file), pkg.ImportPath, nil
+	// 本地导入路径对应的目录是相对路径，统一转换为完整路径
+	if filename, err = filepath.Abs(filepath.Join(pkg.Dir, file)); err != nil {
+		return "", "", &errs.MissingSourceError{File: path, Err: err}
+	}
+	return filename, pkg.ImportPath, nil
 }
 
 // findFuncs 解析文件并返回一段 FuncExtent 描述符
-func (c *converter) findFuncs(name string) ([]*FuncExtent, error) {
+func findFuncs(name string, src []byte) ([]*FuncExtent, error) {
 	fset := token.NewFileSet()
-	parsedFile, err := parser.ParseFile(fset, name, nil, 0)
+	parsedFile, err := parser.ParseFile(fset, name, src, 0)
 	if err != nil {
-		return nil, err
+		return nil, &errs.ParseError{File: name, Err: err}
 	}
 	visitor := &FuncVisitor{fset: fset}
 	ast.Walk(visitor, parsedFile)
+	if visitor.err != nil {
+		return nil, &errs.ParseError{File: name, Err: visitor.err}
+	}
 	return visitor.funcs, nil
 }
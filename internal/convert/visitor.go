@@ -21,18 +21,54 @@ type StmtExtent extent
 // FuncExtent 按文件和位置描述函数在源中的范围。
 type FuncExtent struct {
 	extent
-	name  string
-	stmts []*StmtExtent
+	name      string
+	stmts     []*StmtExtent
+	decisions []*decisionExtent
+	// cyclomatic 与 cognitive 是函数的圈复杂度和认知复杂度
+	cyclomatic int
+	cognitive  int
+}
+
+// position 是源中的一个位置
+type position struct {
+	line int
+	col  int
+}
+
+// before 判断 p 是否在 p2 之前
+func (p position) before(p2 position) bool {
+	return p.line < p2.line || (p.line == p2.line && p.col < p2.col)
+}
+
+// decisionExtent 描述一个判定点及其各分支在源中的范围。
+// 覆盖率 profile 只在块级别计数，无法推算 && 与 || 短路求值的各个分支，因此不记录它们。
+type decisionExtent struct {
+	kind     string
+	pos      position // 判定点关键字的位置
+	outcomes []*outcomeExtent
+}
+
+// outcomeExtent 描述判定点的一个分支在源中的范围
+type outcomeExtent struct {
+	label string
+	start position
+	end   position
+	// implicit 表示源码中没有对应的分支体(没有 else 的 if、没有 default 的 switch)，执行次数需要推算
+	implicit bool
 }
 
 // FuncVisitor 实现了为文件构建函数位置列表的访问者。
 type FuncVisitor struct {
 	fset  *token.FileSet
 	funcs []*FuncExtent
+	err   error
 }
 
-// Visit 实现了 ast.Visitor 接口。
+// Visit 实现了 ast.Visitor 接口。遇到无法处理的节点时停止遍历并记录错误。
 func (v *FuncVisitor) Visit(node ast.Node) ast.Visitor {
+	if v.err != nil {
+		return nil
+	}
 	var body *ast.BlockStmt
 	var name string
 	switch n := node.(type) {
@@ -58,10 +94,17 @@ func (v *FuncVisitor) Visit(node ast.Node) ast.Visitor {
 				endLine:     end.Line,
 				endCol:      end.Column,
 			},
+			// StmtVisitor 会改写 else if 节点，需要在其之前计算复杂度
+			cyclomatic: cyclomaticComplexity(body),
+			cognitive:  cognitiveComplexity(body),
 		}
 		v.funcs = append(v.funcs, fe)
 		sv := StmtVisitor{fset: v.fset, function: fe}
 		sv.VisitStmt(body)
+		if sv.err != nil {
+			v.err = sv.err
+			return nil
+		}
 	}
 	return v
 }
@@ -92,9 +135,66 @@ func (v *FuncVisitor) exprName(x ast.Expr) string {
 type StmtVisitor struct {
 	fset     *token.FileSet
 	function *FuncExtent
+	err      error
+}
+
+func (v *StmtVisitor) position(pos token.Pos) position {
+	p := v.fset.Position(pos)
+	return position{line: p.Line, col: p.Column}
+}
+
+// addDecision 记录一个判定点
+func (v *StmtVisitor) addDecision(kind string, pos token.Pos) *decisionExtent {
+	d := &decisionExtent{kind: kind, pos: v.position(pos)}
+	v.function.decisions = append(v.function.decisions, d)
+	return d
 }
 
+// addOutcome 记录判定点的一个分支
+func (v *StmtVisitor) addOutcome(d *decisionExtent, label string, start, end token.Pos, implicit bool) {
+	d.outcomes = append(d.outcomes, &outcomeExtent{
+		label:    label,
+		start:    v.position(start),
+		end:      v.position(end),
+		implicit: implicit,
+	})
+}
+
+// addClauses 记录 switch、select 语句的判定点及各分支
+func (v *StmtVisitor) addClauses(kind string, pos token.Pos, body *ast.BlockStmt) {
+	d := v.addDecision(kind, pos)
+	hasDefault := false
+	for i, stmt := range body.List {
+		end := body.Rbrace
+		if i+1 < len(body.List) {
+			end = body.List[i+1].Pos()
+		}
+		label := "case"
+		switch clause := stmt.(type) {
+		case *ast.CaseClause:
+			if clause.List == nil {
+				label = "default"
+			}
+			v.addOutcome(d, label, clause.Colon, end, false)
+		case *ast.CommClause:
+			if clause.Comm == nil {
+				label = "default"
+			}
+			v.addOutcome(d, label, clause.Colon, end, false)
+		}
+		hasDefault = hasDefault || label == "default"
+	}
+	// select 会阻塞直到某个分支就绪，没有隐含分支
+	if !hasDefault && kind != "select" {
+		v.addOutcome(d, "default", body.Rbrace, body.Rbrace, true)
+	}
+}
+
+// VisitStmt 记录语句范围。遇到无法处理的节点时记录错误并停止。
 func (v *StmtVisitor) VisitStmt(s ast.Stmt) {
+	if v.err != nil {
+		return
+	}
 	var statements *[]ast.Stmt
 	switch s := s.(type) {
 	case *ast.BlockStmt:
@@ -115,6 +215,11 @@ func (v *StmtVisitor) VisitStmt(s ast.Stmt) {
 		if s.Init != nil {
 			v.VisitStmt(s.Init)
 		}
+		d := v.addDecision("if", s.If)
+		v.addOutcome(d, "then", s.Body.Lbrace, s.Body.Rbrace, false)
+		if s.Else == nil {
+			v.addOutcome(d, "else", s.Body.Rbrace, s.Body.Rbrace, true)
+		}
 		v.VisitStmt(s.Body)
 		if s.Else != nil {
 			// 从 go.tools/cmd/convert 复制的代码，用于处理“if x {} else if y {}
@@ -130,8 +235,10 @@ func (v *StmtVisitor) VisitStmt(s ast.Stmt) {
 			case *ast.BlockStmt:
 				stmt.Lbrace -= backupToElse // 所以这个块看起来像是从“else”开始的。
 			default:
-				panic("unexpected node type in if")
+				v.err = fmt.Errorf("unexpected node type %T in if at %s", stmt, v.fset.Position(stmt.Pos()))
+				return
 			}
+			v.addOutcome(d, "else", s.Else.Pos(), s.Else.End(), false)
 			v.VisitStmt(s.Else)
 		}
 	case *ast.LabeledStmt:
@@ -139,17 +246,20 @@ func (v *StmtVisitor) VisitStmt(s ast.Stmt) {
 	case *ast.RangeStmt:
 		v.VisitStmt(s.Body)
 	case *ast.SelectStmt:
+		v.addClauses("select", s.Select, s.Body)
 		v.VisitStmt(s.Body)
 	case *ast.SwitchStmt:
 		if s.Init != nil {
 			v.VisitStmt(s.Init)
 		}
+		v.addClauses("switch", s.Switch, s.Body)
 		v.VisitStmt(s.Body)
 	case *ast.TypeSwitchStmt:
 		if s.Init != nil {
 			v.VisitStmt(s.Init)
 		}
 		v.VisitStmt(s.Assign)
+		v.addClauses("typeswitch", s.Switch, s.Body)
 		v.VisitStmt(s.Body)
 	}
 	if statements == nil {
@@ -5,7 +5,6 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"os/exec"
 	"sort"
 	"strings"
 
@@ -14,6 +13,9 @@ import (
 	"github.com/spf13/cast"
 )
 
+// DefaultTargetBranch 是默认被对比的分支
+const DefaultTargetBranch = "master"
+
 type diff struct {
 	CurrentBranch     string
 	TargetBranch      string
@@ -73,10 +75,9 @@ func Do(currentBranch, targetBranch string, hashIdsRange []string) (d *diff, err
 }
 
 func (d *diff) listDiffCommitHashIds() error {
-	cmd := exec.Command("git", "log", fmt.Sprintf("%s..%s", d.TargetBranch, d.CurrentBranch), "--oneline")
-	output, err := cmd.CombinedOutput()
+	output, err := utils.RunGit("", "log", fmt.Sprintf("%s..%s", d.TargetBranch, d.CurrentBranch), "--oneline")
 	if err != nil {
-		return fmt.Errorf("failed to get commit hash information for differences between branches. err: %v", err)
+		return fmt.Errorf("failed to get commit hash information for differences between branches. err: %w", err)
 	}
 
 	commitHashIds := make([]string, 0)
@@ -105,10 +106,9 @@ func (d *diff) listDiffCommitHashIds() error {
 }
 
 func (d *diff) listDiffCommitHashIdsWithLimit(hashIdsRange []string) error {
-	cmd := exec.Command("git", "log", fmt.Sprintf("%s..%s", d.TargetBranch, d.CurrentBranch), "--oneline")
-	output, err := cmd.CombinedOutput()
+	output, err := utils.RunGit("", "log", fmt.Sprintf("%s..%s", d.TargetBranch, d.CurrentBranch), "--oneline")
 	if err != nil {
-		return fmt.Errorf("failed to get commit hash information for differences between branches. err: %v", err)
+		return fmt.Errorf("failed to get commit hash information for differences between branches. err: %w", err)
 	}
 
 	var (
@@ -163,10 +163,9 @@ func (d *diff) listDiffCommitHashIdsWithLimit(hashIdsRange []string) error {
 
 func (d *diff) listCommitModifyFiles() error {
 	for hashId := range d.commitHashIdSet {
-		cmd := exec.Command("git", "show", hashId, "--name-only")
-		output, err := cmd.CombinedOutput()
+		output, err := utils.RunGit("", "show", hashId, "--name-only")
 		if err != nil {
-			return fmt.Errorf("failed to get modify files information from commit hash id. err: %v", err)
+			return fmt.Errorf("failed to get modify files information from commit hash id. err: %w", err)
 		}
 		br := bufio.NewReader(bytes.NewBuffer(output))
 		for {
@@ -192,10 +191,9 @@ func (d *diff) listCommitModifyFiles() error {
 func (d *diff) listCommitModifyLineNos() error {
 	for path, lineNos := range d.filePathM2LineNos {
 		// https://git-scm.com/docs/git-blame
-		cmd := exec.Command("git", "blame", path, "-w", "-s", "--show-name")
-		output, err := cmd.CombinedOutput()
+		output, err := utils.RunGit("", "blame", path, "-w", "-s", "--show-name")
 		if err != nil {
-			return fmt.Errorf("failed to get modify codes information from file[%s]. err: %v", path, err)
+			return fmt.Errorf("failed to get modify codes information from file[%s]. err: %w", path, err)
 		}
 
 		br := bufio.NewReader(bytes.NewBuffer(output))
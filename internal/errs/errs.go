@@ -0,0 +1,75 @@
+package errs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingSourceError 表示找不到(或无法读取)源文件。
+type MissingSourceError struct {
+	File string
+	Err  error
+}
+
+func (e *MissingSourceError) Error() string {
+	return fmt.Sprintf("missing source file[%s]. err: %v", e.File, e.Err)
+}
+
+func (e *MissingSourceError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError 表示解析文件失败(覆盖率 profile、go 源文件、json 等)。
+type ParseError struct {
+	File string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse file[%s]. err: %v", e.File, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// GitError 表示执行 git 命令失败。
+type GitError struct {
+	Args   []string
+	Output string
+	Err    error
+}
+
+func (e *GitError) Error() string {
+	msg := fmt.Sprintf("failed to run git %s. err: %v", strings.Join(e.Args, " "), e.Err)
+	if output := strings.TrimSpace(e.Output); len(output) > 0 {
+		msg += ", output: " + output
+	}
+	return msg
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// MergeError 表示合并覆盖率数据时两份数据不匹配。
+type MergeError struct {
+	// Name 是不匹配的包、函数或语句的名称
+	Name   string
+	Reason string
+}
+
+func (e *MergeError) Error() string {
+	return fmt.Sprintf("failed to merge %s: %s", e.Name, e.Reason)
+}
+
+// SkippedFile 记录在“继续执行”模式下被跳过的文件及原因。
+type SkippedFile struct {
+	File   string
+	Reason string
+}
+
+// NewSkippedFile 根据错误创建被跳过的文件记录
+func NewSkippedFile(file string, err error) *SkippedFile {
+	return &SkippedFile{File: file, Reason: err.Error()}
+}
@@ -0,0 +1,68 @@
+package gate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+const (
+	RuleFull = "full" // 全量覆盖率门禁
+	RuleDiff = "diff" // 增量覆盖率门禁
+)
+
+// Rule 是一条覆盖率门禁规则
+type Rule struct {
+	// Name 是规则名称，如 full、diff
+	Name string
+	// Metric 是判定使用的统计口径
+	Metric metadata.Metric
+	// Min 是要求的最低覆盖率百分比
+	Min float64
+}
+
+// Result 是一条规则的判定结果
+type Result struct {
+	Rule     *Rule
+	Coverage metadata.Coverage
+	Passed   bool
+}
+
+// String 格式化判定结果
+func (r *Result) String() string {
+	status := "PASS"
+	if !r.Passed {
+		status = "FAIL"
+	}
+	return fmt.Sprintf("%s %s %s coverage: %.2f%% (%d/%d), required: %.2f%%",
+		status, r.Rule.Name, r.Rule.Metric, r.Coverage.Percent(), r.Coverage.Reached, r.Coverage.Total, r.Rule.Min)
+}
+
+// Evaluate 按规则判定覆盖率数据的总体覆盖率是否达标
+func Evaluate(rule *Rule, ps utils.Packages) *Result {
+	var c metadata.Coverage
+	for _, p := range ps {
+		c.Add(p.Coverage(rule.Metric))
+	}
+	return &Result{
+		Rule:     rule,
+		Coverage: c,
+		Passed:   c.Percent() >= rule.Min,
+	}
+}
+
+// Check 汇总判定结果，存在未达标的规则时返回错误
+func Check(results []*Result) error {
+	failed := make([]string, 0)
+	for _, r := range results {
+		if !r.Passed {
+			failed = append(failed, r.String())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("coverage gate failed:\n%s", strings.Join(failed, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package gate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func TestEvaluate(t *testing.T) {
+	ps := utils.Packages{
+		&metadata.Package{Name: "p", Functions: []*metadata.Function{{
+			Name:       "f",
+			Statements: []*metadata.Statement{{Reached: 1}, {Reached: 0}, {Reached: 0}, {Reached: 2}},
+			Blocks: []*metadata.Block{
+				{StartLine: 1, EndLine: 2, EndCol: 5, Count: 1},
+				{StartLine: 3, EndLine: 5, EndCol: 1, Count: 0},
+			},
+		}}},
+	}
+
+	tests := []struct {
+		metric     metadata.Metric
+		min        float64
+		wantPct    float64
+		wantPassed bool
+	}{
+		{metadata.MetricStatement, 50, 50, true},
+		{metadata.MetricLine, 60, 50, false}, // 第 5 行在块结束于行首时不计入
+		{metadata.MetricBlock, 50, 50, true},
+		{metadata.MetricBlock, 50.01, 50, false},
+	}
+	for _, tt := range tests {
+		r := Evaluate(&Rule{Name: RuleFull, Metric: tt.metric, Min: tt.min}, ps)
+		if r.Coverage.Percent() != tt.wantPct || r.Passed != tt.wantPassed {
+			t.Errorf("Evaluate(%s, %.2f) = %.2f%% passed=%v, want %.2f%% passed=%v",
+				tt.metric, tt.min, r.Coverage.Percent(), r.Passed, tt.wantPct, tt.wantPassed)
+		}
+	}
+}
+
+func TestEvaluateEmpty(t *testing.T) {
+	r := Evaluate(&Rule{Name: RuleDiff, Metric: metadata.MetricStatement, Min: 80}, nil)
+	if !r.Passed {
+		t.Error("Expected an empty dataset to pass")
+	}
+	if err := Check([]*Result{r}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	pkg := func(name string, reached ...int64) *metadata.Package {
+		f := &metadata.Function{Name: "f"}
+		for _, r := range reached {
+			f.Statements = append(f.Statements, &metadata.Statement{Reached: r})
+		}
+		return &metadata.Package{Name: name, Functions: []*metadata.Function{f}}
+	}
+	full := utils.Packages{pkg("a", 1, 1), pkg("b", 1, 0, 0, 0)}
+	diff := utils.Packages{pkg("b", 0)}
+
+	var buf bytes.Buffer
+	err := WriteJUnit(&buf, []*Evaluation{
+		{Rule: &Rule{Name: RuleFull, Metric: metadata.MetricStatement, Min: 50}, Packages: full},
+		{Rule: &Rule{Name: RuleDiff, Metric: metadata.MetricStatement, Min: 80}, Packages: diff},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="coverage gate" tests="6" failures="3">
+  <testsuite name="(total)" tests="2" failures="1" skipped="0">
+    <testcase name="full statement coverage &gt;= 50.00%" classname="(total)" time="0"></testcase>
+    <testcase name="diff statement coverage &gt;= 80.00%" classname="(total)" time="0">
+      <failure message="diff statement coverage 0.00% (0/1) is below the required 80.00%" type="coverage"></failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="a" tests="2" failures="0" skipped="1">
+    <testcase name="full statement coverage &gt;= 50.00%" classname="a" time="0"></testcase>
+    <testcase name="diff statement coverage &gt;= 80.00%" classname="a" time="0">
+      <skipped message="no diff coverage data"></skipped>
+    </testcase>
+  </testsuite>
+  <testsuite name="b" tests="2" failures="2" skipped="0">
+    <testcase name="full statement coverage &gt;= 50.00%" classname="b" time="0">
+      <failure message="full statement coverage 25.00% (1/4) is below the required 50.00%" type="coverage"></failure>
+    </testcase>
+    <testcase name="diff statement coverage &gt;= 80.00%" classname="b" time="0">
+      <failure message="diff statement coverage 0.00% (0/1) is below the required 80.00%" type="coverage"></failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if buf.String() != want {
+		t.Errorf("WriteJUnit() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,134 @@
+package gate
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// TotalSuite 是 JUnit 报告中汇总所有包的测试套件名，门禁按它的结果判定
+const TotalSuite = "(total)"
+
+// Evaluation 是一条规则及其判定的覆盖率数据
+type Evaluation struct {
+	Rule     *Rule
+	Packages utils.Packages
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// WriteJUnit 将门禁判定结果以 JUnit XML 格式输出到 w。
+// 每个包是一个测试套件，每条规则是其中的一个测试用例；另有 TotalSuite 套件记录总体的判定结果。
+// 包不在某条规则的数据中时(如没有新代码的包)，该用例标记为跳过。
+func WriteJUnit(w io.Writer, evaluations []*Evaluation) error {
+	doc := &junitTestSuites{Name: "coverage gate"}
+	total := &junitTestSuite{Name: TotalSuite}
+	names := make(map[string]struct{})
+	for _, e := range evaluations {
+		total.add(junitCase(TotalSuite, Evaluate(e.Rule, e.Packages)))
+		for _, p := range e.Packages {
+			names[p.Name] = struct{}{}
+		}
+	}
+	doc.add(total)
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		suite := &junitTestSuite{Name: name}
+		for _, e := range evaluations {
+			var ps utils.Packages
+			for _, p := range e.Packages {
+				if p.Name == name {
+					ps = append(ps, p)
+				}
+			}
+			if len(ps) == 0 {
+				suite.add(&junitTestCase{Name: caseName(e.Rule), ClassName: name, Time: "0",
+					Skipped: &junitMessage{Message: fmt.Sprintf("no %s coverage data", e.Rule.Name)}})
+				continue
+			}
+			suite.add(junitCase(name, Evaluate(e.Rule, ps)))
+		}
+		doc.add(suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// caseName 返回规则对应的用例名，如 diff line coverage >= 80.00%
+func caseName(r *Rule) string {
+	return fmt.Sprintf("%s %s coverage >= %.2f%%", r.Name, r.Metric, r.Min)
+}
+
+// junitCase 将判定结果转换为测试用例，未达标时失败信息中包含实际与要求的覆盖率
+func junitCase(class string, r *Result) *junitTestCase {
+	c := &junitTestCase{Name: caseName(r.Rule), ClassName: class, Time: "0"}
+	if !r.Passed {
+		c.Failure = &junitMessage{
+			Message: fmt.Sprintf("%s %s coverage %.2f%% (%d/%d) is below the required %.2f%%",
+				r.Rule.Name, r.Rule.Metric, r.Coverage.Percent(), r.Coverage.Reached, r.Coverage.Total, r.Rule.Min),
+			Type: "coverage",
+		}
+	}
+	return c
+}
+
+func (s *junitTestSuite) add(c *junitTestCase) {
+	s.Cases = append(s.Cases, c)
+	s.Tests++
+	if c.Failure != nil {
+		s.Failures++
+	}
+	if c.Skipped != nil {
+		s.Skipped++
+	}
+}
+
+func (s *junitTestSuites) add(suite *junitTestSuite) {
+	s.Suites = append(s.Suites, suite)
+	s.Tests += suite.Tests
+	s.Failures += suite.Failures
+}
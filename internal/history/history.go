@@ -0,0 +1,165 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/errs"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+const (
+	NameTotal = "total" // 全量覆盖率的趋势名称
+	NameDiff  = "diff"  // 增量覆盖率的趋势名称
+
+	snapshotExt = ".json"
+	// tolerance 是判定覆盖率下降时忽略的百分比误差
+	tolerance = 0.005
+)
+
+// Snapshot 是某次生成报告时的覆盖率摘要
+type Snapshot struct {
+	Time   time.Time
+	Commit string `json:",omitempty"`
+	Branch string `json:",omitempty"`
+	// Metric 是摘要使用的统计口径
+	Metric metadata.Metric
+	// Total 是全量覆盖情况
+	Total metadata.Coverage
+	// Diff 是增量覆盖情况，没有生成增量报告时为 nil
+	Diff *metadata.Coverage `json:",omitempty"`
+	// Packages 是以包名为键的全量覆盖情况
+	Packages map[string]metadata.Coverage
+}
+
+// NewSnapshot 按统计口径汇总全量与增量覆盖率数据。diff 为 nil 时不记录增量覆盖率
+func NewSnapshot(ps, diff utils.Packages, metric metadata.Metric) *Snapshot {
+	s := &Snapshot{
+		Time:     time.Now(),
+		Metric:   metric,
+		Packages: make(map[string]metadata.Coverage, len(ps)),
+	}
+	for _, p := range ps {
+		c := p.Coverage(metric)
+		s.Packages[p.Name] = c
+		s.Total.Add(c)
+	}
+	if diff != nil {
+		s.Diff = &metadata.Coverage{}
+		for _, p := range diff {
+			s.Diff.Add(p.Coverage(metric))
+		}
+	}
+	return s
+}
+
+// Change 是两次快照之间某项覆盖率的变化
+type Change struct {
+	// Name 是变化项的名称：total、diff 或包名
+	Name     string
+	Previous float64
+	Current  float64
+}
+
+// Delta 返回覆盖率百分比的变化量
+func (c *Change) Delta() float64 {
+	return c.Current - c.Previous
+}
+
+// Regressions 返回 cur 相对 prev 覆盖率下降的项，按 total、diff、包名的顺序排列。
+// 只比较两次快照都存在的项，统计口径不同的快照之间不做比较。
+func Regressions(prev, cur *Snapshot) []*Change {
+	var rv []*Change
+	if prev.Metric != cur.Metric {
+		return nil
+	}
+	add := func(name string, p, c metadata.Coverage) {
+		if c.Percent() < p.Percent()-tolerance {
+			rv = append(rv, &Change{Name: name, Previous: p.Percent(), Current: c.Percent()})
+		}
+	}
+	add(NameTotal, prev.Total, cur.Total)
+	if prev.Diff != nil && cur.Diff != nil {
+		add(NameDiff, *prev.Diff, *cur.Diff)
+	}
+	names := make([]string, 0, len(cur.Packages))
+	for name := range cur.Packages {
+		if _, ok := prev.Packages[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, prev.Packages[name], cur.Packages[name])
+	}
+	return rv
+}
+
+// Store 是以目录保存快照的历史库，每个快照是一个 json 文件
+type Store struct {
+	dir string
+}
+
+// Open 打开历史库目录，不存在时创建
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to open history store. err: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Append 保存一个快照
+func (s *Store) Append(snap *Snapshot) error {
+	commit := snap.Commit
+	if len(commit) > 8 {
+		commit = commit[:8]
+	}
+	name := snap.Time.UTC().Format("20060102T150405.000000000Z")
+	if commit != "" {
+		name += "-" + commit
+	}
+	file, err := utils.CreateFile(s.dir, name+snapshotExt)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot. err: %w", err)
+	}
+	defer file.Close()
+	if err = json.NewEncoder(file).Encode(snap); err != nil {
+		return fmt.Errorf("failed to save snapshot. err: %w", err)
+	}
+	return nil
+}
+
+// List 按时间顺序返回历史快照。branch 非空时只返回该分支的快照
+func (s *Store) List(branch string) ([]*Snapshot, error) {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history store. err: %w", err)
+	}
+	snaps := make([]*Snapshot, 0, len(infos))
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), snapshotExt) {
+			continue
+		}
+		path := filepath.Join(s.dir, info.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot. err: %w", err)
+		}
+		snap := &Snapshot{}
+		if err = json.Unmarshal(data, snap); err != nil {
+			return nil, &errs.ParseError{File: path, Err: err}
+		}
+		if branch == "" || snap.Branch == branch {
+			snaps = append(snaps, snap)
+		}
+	}
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Time.Before(snaps[j].Time) })
+	return snaps, nil
+}
@@ -0,0 +1,72 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+)
+
+func TestStore(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	snaps := []*Snapshot{
+		{Time: now.Add(time.Minute), Commit: "bbbbbbbbbb", Branch: "dev", Metric: metadata.MetricStatement},
+		{Time: now, Commit: "aaaaaaaaaa", Branch: "dev", Metric: metadata.MetricStatement},
+		{Time: now.Add(2 * time.Minute), Branch: "master", Metric: metadata.MetricStatement},
+	}
+	for _, s := range snaps {
+		if err = store.Append(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := store.List("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Commit != "aaaaaaaaaa" || got[1].Commit != "bbbbbbbbbb" {
+		t.Errorf("List(dev) = %+v, want the two dev snapshots in time order", got)
+	}
+	if got, _ = store.List(""); len(got) != 3 {
+		t.Errorf("List() returned %d snapshots, want 3", len(got))
+	}
+}
+
+func TestRegressions(t *testing.T) {
+	prev := &Snapshot{
+		Metric: metadata.MetricStatement,
+		Total:  metadata.Coverage{Reached: 5, Total: 10},
+		Diff:   &metadata.Coverage{Reached: 1, Total: 2},
+		Packages: map[string]metadata.Coverage{
+			"a":       {Reached: 3, Total: 4},
+			"b":       {Reached: 2, Total: 6},
+			"removed": {Reached: 1, Total: 1},
+		},
+	}
+	cur := &Snapshot{
+		Metric: metadata.MetricStatement,
+		Total:  metadata.Coverage{Reached: 5, Total: 12},
+		Diff:   &metadata.Coverage{Reached: 2, Total: 2},
+		Packages: map[string]metadata.Coverage{
+			"a":   {Reached: 3, Total: 6},
+			"b":   {Reached: 3, Total: 6},
+			"new": {Reached: 0, Total: 1},
+		},
+	}
+	got := Regressions(prev, cur)
+	if len(got) != 2 || got[0].Name != NameTotal || got[1].Name != "a" {
+		t.Fatalf("Regressions() = %+v, want total and a", got)
+	}
+	if got[1].Delta() != -25 {
+		t.Errorf("Delta() = %v, want -25", got[1].Delta())
+	}
+
+	cur.Metric = metadata.MetricLine
+	if got = Regressions(prev, cur); got != nil {
+		t.Errorf("Regressions() with different metrics = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,99 @@
+// Package impact 按覆盖率数据中记录的测试归因，选出执行过新代码行的测试
+package impact
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// PackageTests 是一个测试包中受影响的测试
+type PackageTests struct {
+	// Package 是测试所在包的导入路径
+	Package string
+	// Tests 是测试函数名(有序)
+	Tests []string
+}
+
+// RunPattern 返回只运行这些测试的 go test -run 正则。Tests 只能是顶层测试函数，-run 按 / 分层匹配子测试
+func (p *PackageTests) RunPattern() string {
+	names := make([]string, 0, len(p.Tests))
+	for _, t := range p.Tests {
+		names = append(names, regexp.QuoteMeta(t))
+	}
+	return "^(" + strings.Join(names, "|") + ")$"
+}
+
+// Result 是测试选择的结果
+type Result struct {
+	// Packages 是受影响的测试，按包名排序
+	Packages []*PackageTests
+	// Untested 是没有任何测试执行过的新代码行数，这些行的变更无法由选出的测试验证
+	Untested int
+}
+
+// Select 从按差异裁剪后的覆盖率数据中选出执行过新代码行的测试。
+// diffPackages 中的覆盖块需要带有测试归因(见 metadata.Block.Tests)。
+func Select(diffPackages utils.Packages) *Result {
+	rv := &Result{}
+	tests := make(map[string]map[string]struct{})
+	for _, pkg := range diffPackages {
+		for _, f := range pkg.Functions {
+			lineTests := f.LineTests()
+			for line := range f.NewLineSet {
+				if !instrumented(f, line) {
+					continue
+				}
+				if len(lineTests[line]) == 0 {
+					rv.Untested++
+					continue
+				}
+				for _, id := range lineTests[line] {
+					testPkg, name := metadata.SplitTestID(id)
+					if tests[testPkg] == nil {
+						tests[testPkg] = make(map[string]struct{})
+					}
+					tests[testPkg][name] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for pkg, names := range tests {
+		p := &PackageTests{Package: pkg}
+		for name := range names {
+			p.Tests = append(p.Tests, name)
+		}
+		sort.Strings(p.Tests)
+		rv.Packages = append(rv.Packages, p)
+	}
+	sort.Slice(rv.Packages, func(i, j int) bool { return rv.Packages[i].Package < rv.Packages[j].Package })
+	return rv
+}
+
+// HasTests 判断覆盖率数据中是否记录了测试归因
+func HasTests(ps utils.Packages) bool {
+	for _, pkg := range ps {
+		for _, f := range pkg.Functions {
+			for _, b := range f.Blocks {
+				if len(b.Tests) > 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// instrumented 判断该行是否位于某个覆盖块内
+func instrumented(f *metadata.Function, line int) bool {
+	for _, b := range f.Blocks {
+		if line >= b.StartLine && line <= b.LastLine() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package impact
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func TestSelect(t *testing.T) {
+	block := func(line int, tests ...string) *metadata.Block {
+		return &metadata.Block{StartLine: line, StartCol: 2, EndLine: line, EndCol: 10, NumStmt: 1, Tests: tests}
+	}
+	ps := utils.Packages{
+		{Name: "example.com/calc", Functions: []*metadata.Function{
+			{
+				Name: "Abs",
+				Blocks: []*metadata.Block{
+					block(5, "example.com/calc.TestAbs", "example.com/calc.TestMax"),
+					block(6, "example.com/calc.TestAbs"),
+					block(8, "example.com/e2e.TestCalc"),
+					block(9),
+				},
+				// 第 7 行没有覆盖块，第 9 行没有测试执行过
+				NewLineSet: map[int]struct{}{6: {}, 7: {}, 8: {}, 9: {}},
+			},
+		}},
+	}
+
+	r := Select(ps)
+	want := []*PackageTests{
+		{Package: "example.com/calc", Tests: []string{"TestAbs"}},
+		{Package: "example.com/e2e", Tests: []string{"TestCalc"}},
+	}
+	if !reflect.DeepEqual(r.Packages, want) {
+		t.Errorf("Packages = %+v, want %+v", r.Packages, want)
+	}
+	if r.Untested != 1 {
+		t.Errorf("Untested = %d, want 1", r.Untested)
+	}
+	if !HasTests(ps) {
+		t.Error("HasTests() = false, want true")
+	}
+
+	p := &PackageTests{Tests: []string{"TestA", "TestB"}}
+	if got, want := p.RunPattern(), "^(TestA|TestB)$"; got != want {
+		t.Errorf("RunPattern() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lamber92/go-cover/internal/errs"
+)
+
+// Block 是 profile 中记录的原始覆盖块。
+type Block struct {
+	// StartLine、StartCol 是块的起始位置
+	StartLine int `json:"StartLine,omitempty"`
+	StartCol  int `json:"StartCol,omitempty"`
+
+	// EndLine、EndCol 是块的结束位置
+	EndLine int `json:"EndLine,omitempty"`
+	EndCol  int `json:"EndCol,omitempty"`
+
+	// NumStmt 是块中由 go tool cover 统计的语句数
+	NumStmt int `json:"NumStmt,omitempty"`
+
+	// Count 是块被执行的次数
+	Count int64 `json:"Count,omitempty"`
+
+	// Tests 是执行过此块的测试，格式见 TestID。只在按测试归因时记录
+	Tests []string `json:"Tests,omitempty"`
+}
+
+// Accumulate 会将提供的 Block 中的覆盖率信息累积到此 Block 中。
+func (b *Block) Accumulate(b2 *Block) error {
+	if b.StartLine != b2.StartLine || b.StartCol != b2.StartCol || b.EndLine != b2.EndLine || b.EndCol != b2.EndCol {
+		return &errs.MergeError{
+			Name: fmt.Sprintf("block@%d.%d", b.StartLine, b.StartCol),
+			Reason: fmt.Sprintf("block ranges do not match: %d.%d,%d.%d != %d.%d,%d.%d",
+				b.StartLine, b.StartCol, b.EndLine, b.EndCol, b2.StartLine, b2.StartCol, b2.EndLine, b2.EndCol),
+		}
+	}
+	b.Count += b2.Count
+	for _, t := range b2.Tests {
+		b.AddTest(t)
+	}
+	return nil
+}
+
+// AddTest 登记执行过此块的测试，已登记的测试会被忽略
+func (b *Block) AddTest(test string) {
+	i := sort.SearchStrings(b.Tests, test)
+	if i < len(b.Tests) && b.Tests[i] == test {
+		return
+	}
+	b.Tests = append(b.Tests, "")
+	copy(b.Tests[i+1:], b.Tests[i:])
+	b.Tests[i] = test
+}
+
+// LastLine 返回块实际包含代码的最后一行。块结束于某行行首时，该行不计入。
+func (b *Block) LastLine() int {
+	if b.EndCol <= 1 && b.EndLine > b.StartLine {
+		return b.EndLine - 1
+	}
+	return b.EndLine
+}
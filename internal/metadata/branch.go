@@ -0,0 +1,55 @@
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/lamber92/go-cover/internal/errs"
+)
+
+// Branch 是一个判定点(if、switch、type switch、select)及其各分支的执行情况。
+type Branch struct {
+	// Kind 是判定点的类型：if、switch、typeswitch、select
+	Kind string `json:"Kind,omitempty"`
+
+	// Line 是判定点关键字所在行
+	Line int `json:"Line,omitempty"`
+
+	// Outcomes 是判定点的各个分支
+	Outcomes []*Outcome `json:"Outcomes,omitempty"`
+}
+
+// Outcome 是判定点的一个分支。
+type Outcome struct {
+	// Label 是分支的名称，如 then、else、case、default
+	Label string `json:"Label,omitempty"`
+
+	// Line 是分支起始行
+	Line int `json:"Line,omitempty"`
+
+	// Count 是分支被执行的次数。隐含分支(没有 else 的 if、没有 default 的 switch)的次数由判定点与其余分支的次数推算。
+	Count int64 `json:"Count,omitempty"`
+}
+
+// Reached 返回判定点被执行过的分支数
+func (b *Branch) Reached() (n int) {
+	for _, o := range b.Outcomes {
+		if o.Count > 0 {
+			n++
+		}
+	}
+	return
+}
+
+// Accumulate 会将提供的 Branch 中的覆盖率信息累积到此 Branch 中。
+func (b *Branch) Accumulate(b2 *Branch) error {
+	if b.Kind != b2.Kind || b.Line != b2.Line || len(b.Outcomes) != len(b2.Outcomes) {
+		return &errs.MergeError{
+			Name:   fmt.Sprintf("%s@%d", b.Kind, b.Line),
+			Reason: fmt.Sprintf("branches do not match: %s@%d(%d) != %s@%d(%d)", b.Kind, b.Line, len(b.Outcomes), b2.Kind, b2.Line, len(b2.Outcomes)),
+		}
+	}
+	for i, o := range b.Outcomes {
+		o.Count += b2.Outcomes[i].Count
+	}
+	return nil
+}
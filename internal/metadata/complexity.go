@@ -0,0 +1,19 @@
+package metadata
+
+import "math"
+
+// CRAP 返回函数的 CRAP(Change Risk Anti-Patterns) 分数：comp^2 * (1 - cov)^3 + comp。
+// comp 为圈复杂度，cov 为语句覆盖率。分数越高，修改函数的风险越大。
+func (f *Function) CRAP() float64 {
+	comp := float64(f.complexity())
+	uncovered := 1 - f.Coverage(MetricStatement).Percent()/100
+	return comp*comp*math.Pow(uncovered, 3) + comp
+}
+
+// complexity 返回函数的圈复杂度。旧版本生成的数据没有记录复杂度，按 1 处理。
+func (f *Function) complexity() int {
+	if f.Cyclomatic < 1 {
+		return 1
+	}
+	return f.Cyclomatic
+}
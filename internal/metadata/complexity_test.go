@@ -0,0 +1,29 @@
+package metadata
+
+import "testing"
+
+func TestCRAP(t *testing.T) {
+	tests := []struct {
+		name       string
+		cyclomatic int
+		reached    []int64 // 各语句的执行次数
+		want       float64
+	}{
+		{"uncovered", 3, []int64{0, 0, 0, 0}, 12},
+		{"partially covered", 3, []int64{1, 1, 0, 0}, 4.125},
+		{"fully covered", 3, []int64{1, 2, 3, 4}, 3},
+		{"no statements", 3, nil, 3},
+		{"complexity not recorded", 0, []int64{0}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Function{Cyclomatic: tt.cyclomatic}
+			for _, r := range tt.reached {
+				f.Statements = append(f.Statements, &Statement{Reached: r})
+			}
+			if got := f.CRAP(); got != tt.want {
+				t.Errorf("CRAP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
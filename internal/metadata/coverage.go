@@ -0,0 +1,126 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Metric 是覆盖率的统计口径。
+type Metric string
+
+const (
+	MetricStatement Metric = "statement" // 语句覆盖率(按 AST 语句统计)
+	MetricLine      Metric = "line"      // 行覆盖率(按被插桩的代码行统计)
+	MetricBlock     Metric = "block"     // 块覆盖率(按 profile 中的原始块统计)
+	MetricBranch    Metric = "branch"    // 分支覆盖率(按 if、switch、select 的各分支统计)
+)
+
+// Metrics 是所有支持的统计口径
+var Metrics = []Metric{MetricStatement, MetricLine, MetricBlock, MetricBranch}
+
+// ParseMetric 解析统计口径
+func ParseMetric(s string) (Metric, error) {
+	for _, m := range Metrics {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported coverage metric. [%s]", s)
+}
+
+// Coverage 是某一统计口径下的覆盖情况。
+type Coverage struct {
+	Reached int
+	Total   int
+}
+
+// Percent 返回覆盖率百分比。没有可统计的对象时返回 100。
+func (c Coverage) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Reached) / float64(c.Total) * 100
+}
+
+// Add 累加另一份覆盖情况
+func (c *Coverage) Add(c2 Coverage) {
+	c.Reached += c2.Reached
+	c.Total += c2.Total
+}
+
+// Coverage 返回函数在指定统计口径下的覆盖情况。
+// 增量数据(NewLineSet 非空)的行覆盖率只统计新代码行。
+func (f *Function) Coverage(m Metric) (c Coverage) {
+	switch m {
+	case MetricStatement:
+		for _, s := range f.Statements {
+			c.Total++
+			if s.Reached > 0 {
+				c.Reached++
+			}
+		}
+	case MetricLine:
+		for line, hit := range f.LineHits() {
+			if len(f.NewLineSet) > 0 {
+				if _, ok := f.NewLineSet[line]; !ok {
+					continue
+				}
+			}
+			c.Total++
+			if hit {
+				c.Reached++
+			}
+		}
+	case MetricBlock:
+		for _, b := range f.Blocks {
+			c.Total++
+			if b.Count > 0 {
+				c.Reached++
+			}
+		}
+	case MetricBranch:
+		for _, b := range f.Branches {
+			c.Total += len(b.Outcomes)
+			c.Reached += b.Reached()
+		}
+	}
+	return
+}
+
+// LineHits 返回函数中每个被插桩的代码行是否被执行。
+func (f *Function) LineHits() map[int]bool {
+	hits := make(map[int]bool)
+	for _, b := range f.Blocks {
+		for line := b.StartLine; line <= b.LastLine(); line++ {
+			hits[line] = hits[line] || b.Count > 0
+		}
+	}
+	return hits
+}
+
+// MissedLines 返回函数中被插桩但未被执行的代码行号(升序)。
+// 增量数据(NewLineSet 非空)只返回新代码行。
+func (f *Function) MissedLines() []int {
+	var lines []int
+	for line, hit := range f.LineHits() {
+		if hit {
+			continue
+		}
+		if len(f.NewLineSet) > 0 {
+			if _, ok := f.NewLineSet[line]; !ok {
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// Coverage 返回包在指定统计口径下的覆盖情况。
+func (p *Package) Coverage(m Metric) (c Coverage) {
+	for _, f := range p.Functions {
+		c.Add(f.Coverage(m))
+	}
+	return
+}
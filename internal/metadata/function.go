@@ -1,6 +1,10 @@
 package metadata
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/lamber92/go-cover/internal/errs"
+)
 
 type Function struct {
 	// Name 是函数的名称。
@@ -22,9 +26,21 @@ type Function struct {
 	// EndLine 是函数的结束行号
 	EndLine int `json:"EndLine,omitempty"`
 
+	// Cyclomatic 是函数的圈复杂度
+	Cyclomatic int `json:"Cyclomatic,omitempty"`
+
+	// Cognitive 是函数的认知复杂度
+	Cognitive int `json:"Cognitive,omitempty"`
+
 	// Statements 是指使用此函数注册的语句。
 	Statements []*Statement `json:"Statements,omitempty"`
 
+	// Blocks 是 profile 中落在此函数内的原始覆盖块。
+	Blocks []*Block `json:"Blocks,omitempty"`
+
+	// Branches 是此函数中的判定点。
+	Branches []*Branch `json:"Branches,omitempty"`
+
 	// NewLineSet 新代码行号集合。用于增量覆盖率。
 	NewLineSet map[int]struct{} `json:"NewLineSet,omitempty"`
 }
@@ -32,16 +48,16 @@ type Function struct {
 // Accumulate 会将提供的 Function 的覆盖率信息累积到此 Function 中。
 func (f *Function) Accumulate(f2 *Function) error {
 	if f.Name != f2.Name {
-		return fmt.Errorf("names do not match: %q != %q", f.Name, f2.Name)
+		return &errs.MergeError{Name: f.Name, Reason: fmt.Sprintf("names do not match: %q != %q", f.Name, f2.Name)}
 	}
 	if f.File != f2.File {
-		return fmt.Errorf("files do not match: %q != %q", f.File, f2.File)
+		return &errs.MergeError{Name: f.Name, Reason: fmt.Sprintf("files do not match: %q != %q", f.File, f2.File)}
 	}
 	if f.Start != f2.Start || f.End != f2.End {
-		return fmt.Errorf("source ranges do not match: %d-%d != %d-%d", f.Start, f.End, f2.Start, f2.End)
+		return &errs.MergeError{Name: f.Name, Reason: fmt.Sprintf("source ranges do not match: %d-%d != %d-%d", f.Start, f.End, f2.Start, f2.End)}
 	}
 	if len(f.Statements) != len(f2.Statements) {
-		return fmt.Errorf("number of statements do not match: %d != %d", len(f.Statements), len(f2.Statements))
+		return &errs.MergeError{Name: f.Name, Reason: fmt.Sprintf("number of statements do not match: %d != %d", len(f.Statements), len(f2.Statements))}
 	}
 	for i, s := range f.Statements {
 		err := s.Accumulate(f2.Statements[i])
@@ -49,5 +65,21 @@ func (f *Function) Accumulate(f2 *Function) error {
 			return err
 		}
 	}
+	if len(f.Blocks) != len(f2.Blocks) {
+		return &errs.MergeError{Name: f.Name, Reason: fmt.Sprintf("number of blocks do not match: %d != %d", len(f.Blocks), len(f2.Blocks))}
+	}
+	for i, b := range f.Blocks {
+		if err := b.Accumulate(f2.Blocks[i]); err != nil {
+			return err
+		}
+	}
+	if len(f.Branches) != len(f2.Branches) {
+		return &errs.MergeError{Name: f.Name, Reason: fmt.Sprintf("number of branches do not match: %d != %d", len(f.Branches), len(f2.Branches))}
+	}
+	for i, b := range f.Branches {
+		if err := b.Accumulate(f2.Branches[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,85 @@
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/lamber92/go-cover/internal/errs"
+)
+
+// 覆盖率模式，见 go test -covermode
+const (
+	ModeSet    = "set"    // 只记录是否执行过，计数为 0 或 1
+	ModeCount  = "count"  // 记录执行次数
+	ModeAtomic = "atomic" // 记录执行次数，并发安全
+)
+
+// countingMode 判断模式是否记录执行次数
+func countingMode(mode string) bool {
+	return mode == ModeCount || mode == ModeAtomic
+}
+
+// MergeMode 检查两份数据的覆盖率模式能否合并，返回合并后的模式。
+// 没有记录模式(旧版本生成的 json)时与任何模式兼容；count 与 atomic 都是执行次数，可以相加；
+// set 与 count、atomic 不能合并，需要先用 SetMode 转换为 set。
+func MergeMode(name, mode, mode2 string) (string, error) {
+	switch {
+	case mode == "" || mode == mode2:
+		return mode2, nil
+	case mode2 == "":
+		return mode, nil
+	case countingMode(mode) && countingMode(mode2):
+		return mode, nil
+	default:
+		return "", &errs.MergeError{Name: name, Reason: fmt.Sprintf("cover modes are not compatible: %q != %q, convert them to %q first", mode, mode2, ModeSet)}
+	}
+}
+
+// SetMode 将包的覆盖率模式转换为 mode。
+// 只支持转换为 set(执行次数大于 0 时记为 1)或在 count、atomic 之间转换；set 无法还原为执行次数。
+func (p *Package) SetMode(mode string) error {
+	switch {
+	case mode == p.Mode:
+	case mode == ModeSet:
+		for _, f := range p.Functions {
+			f.capCounts()
+		}
+	case countingMode(mode) && (p.Mode == "" || countingMode(p.Mode)):
+	default:
+		return fmt.Errorf("cannot convert cover mode %q to %q", p.Mode, mode)
+	}
+	p.Mode = mode
+	return nil
+}
+
+// Merge 按覆盖率模式 mode 将 f2 的覆盖率信息合并到此函数中。
+// set 模式的合并结果是两者之一执行过即为 1，count、atomic 模式的执行次数相加。
+func (f *Function) Merge(f2 *Function, mode string) error {
+	if err := f.Accumulate(f2); err != nil {
+		return err
+	}
+	if mode == ModeSet {
+		f.capCounts()
+	}
+	return nil
+}
+
+// capCounts 将大于 1 的执行次数记为 1，即 set 模式下的合并结果
+func (f *Function) capCounts() {
+	for _, s := range f.Statements {
+		if s.Reached > 1 {
+			s.Reached = 1
+		}
+	}
+	for _, b := range f.Blocks {
+		if b.Count > 1 {
+			b.Count = 1
+		}
+	}
+	for _, b := range f.Branches {
+		for _, o := range b.Outcomes {
+			if o.Count > 1 {
+				o.Count = 1
+			}
+		}
+	}
+}
@@ -1,28 +1,63 @@
 package metadata
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/lamber92/go-cover/internal/errs"
+)
 
 type Package struct {
 	// 名称是包的规范路径。
 	Name string `json:"Name,omitempty"`
 
+	// Mode 是 profile 的覆盖率模式：set、count 或 atomic，见 ModeSet 等
+	Mode string `json:"Mode,omitempty"`
+
 	// Functions 是使用此包注册的函数列表。
 	Functions []*Function `json:"Functions,omitempty"`
+
+	// Sources 是该包中被统计的源文件信息列表。
+	Sources []*Source `json:"Sources,omitempty"`
+}
+
+// Source 按文件路径查找源文件信息。如果没有找到则返回 nil。
+func (p *Package) Source(file string) *Source {
+	for _, s := range p.Sources {
+		if s.File == file {
+			return s
+		}
+	}
+	return nil
+}
+
+// AddSource 登记源文件信息，同一文件只保留首次登记的记录。
+func (p *Package) AddSource(s *Source) {
+	if s == nil || p.Source(s.File) != nil {
+		return
+	}
+	p.Sources = append(p.Sources, s)
 }
 
 // Accumulate 会将提供的 Package 中的覆盖率信息累积到此 Package 中。
 func (p *Package) Accumulate(p2 *Package) error {
 	if p.Name != p2.Name {
-		return fmt.Errorf("names do not match: %q != %q", p.Name, p2.Name)
+		return &errs.MergeError{Name: p.Name, Reason: fmt.Sprintf("names do not match: %q != %q", p.Name, p2.Name)}
 	}
 	if len(p.Functions) != len(p2.Functions) {
-		return fmt.Errorf("function counts do not match: %d != %d", len(p.Functions), len(p2.Functions))
+		return &errs.MergeError{Name: p.Name, Reason: fmt.Sprintf("function counts do not match: %d != %d", len(p.Functions), len(p2.Functions))}
+	}
+	mode, err := MergeMode(p.Name, p.Mode, p2.Mode)
+	if err != nil {
+		return err
 	}
 	for i, f := range p.Functions {
-		err := f.Accumulate(p2.Functions[i])
-		if err != nil {
+		if err := f.Merge(p2.Functions[i], mode); err != nil {
 			return err
 		}
 	}
+	p.Mode = mode
+	for _, s := range p2.Sources {
+		p.AddSource(s)
+	}
 	return nil
 }
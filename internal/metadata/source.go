@@ -0,0 +1,16 @@
+package metadata
+
+// Source 记录转换时被统计的源文件信息，用于渲染报告时校验源文件是否已发生变更。
+type Source struct {
+	// File 是源文件的完整路径。
+	File string `json:"File,omitempty"`
+
+	// Hash 是转换时源文件内容的 sha256 摘要(十六进制)。
+	Hash string `json:"Hash,omitempty"`
+
+	// Commit 是转换时工作区所在的 git 提交点，用于在源文件变更后取回原始内容。
+	Commit string `json:"Commit,omitempty"`
+
+	// Content 是经 gzip 压缩后嵌入的源文件内容，用于脱离源码目录渲染报告。
+	Content []byte `json:"Content,omitempty"`
+}
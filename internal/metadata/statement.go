@@ -2,6 +2,8 @@ package metadata
 
 import (
 	"fmt"
+
+	"github.com/lamber92/go-cover/internal/errs"
 )
 
 type Statement struct {
@@ -24,7 +26,10 @@ type Statement struct {
 // Accumulate 会将提供的 Statement 中的覆盖率信息累积到此 Statement 中。
 func (s *Statement) Accumulate(s2 *Statement) error {
 	if s.Start != s2.Start || s.End != s2.End {
-		return fmt.Errorf("source ranges do not match: %d-%d != %d-%d", s.Start, s.End, s2.Start, s2.End)
+		return &errs.MergeError{
+			Name:   fmt.Sprintf("statement@%d", s.Start),
+			Reason: fmt.Sprintf("source ranges do not match: %d-%d != %d-%d", s.Start, s.End, s2.Start, s2.End),
+		}
 	}
 	s.Reached += s2.Reached
 	return nil
@@ -116,3 +116,84 @@ func TestAccumulateStatement(t *testing.T) {
 		t.Errorf("Expected an error")
 	}
 }
+
+func TestSubtractPackage(t *testing.T) {
+	snapshot := func(reached ...int64) *Package {
+		p := registerPackage("p1")
+		f := registerFunction(p, "f", "file.go", 0, 10)
+		for i, r := range reached {
+			registerStatement(f, i, i+1).Reached = r
+			f.Blocks = append(f.Blocks, &Block{StartLine: i + 1, EndLine: i + 1, EndCol: 2, Count: r})
+		}
+		return p
+	}
+	cur := snapshot(5, 3, 1)
+	// 第 3 个语句的计数器在两次快照之间被清零，清零后执行过 1 次
+	if err := cur.Subtract(snapshot(2, 3, 4)); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int64{3, 0, 1} {
+		if got := cur.Functions[0].Statements[i].Reached; got != want {
+			t.Errorf("statement %d reached %d, want %d", i, got, want)
+		}
+		if got := cur.Functions[0].Blocks[i].Count; got != want {
+			t.Errorf("block %d count %d, want %d", i, got, want)
+		}
+	}
+
+	if err := cur.Subtract(snapshot(1)); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestAccumulateMode(t *testing.T) {
+	pkg := func(mode string, reached int64) *Package {
+		p := registerPackage("p1")
+		p.Mode = mode
+		registerStatement(registerFunction(p, "f", "file.go", 0, 1), 0, 1).Reached = reached
+		return p
+	}
+	reached := func(p *Package) int64 { return p.Functions[0].Statements[0].Reached }
+
+	var tests = [...]struct {
+		a, b        *Package
+		expectPass  bool
+		wantMode    string
+		wantReached int64
+	}{
+		// set 模式按"或"合并
+		{pkg(ModeSet, 1), pkg(ModeSet, 1), true, ModeSet, 1},
+		{pkg(ModeSet, 0), pkg(ModeSet, 1), true, ModeSet, 1},
+		// count、atomic 模式的执行次数相加
+		{pkg(ModeCount, 2), pkg(ModeAtomic, 3), true, ModeCount, 5},
+		// 没有记录模式时与任何模式兼容
+		{pkg("", 2), pkg(ModeCount, 3), true, ModeCount, 5},
+		// set 与 count 不能合并
+		{pkg(ModeSet, 1), pkg(ModeCount, 3), false, "", 0},
+	}
+	for i, test := range tests {
+		err := test.a.Accumulate(test.b)
+		if !test.expectPass {
+			if err == nil {
+				t.Errorf("%d: Expected an error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if test.a.Mode != test.wantMode || reached(test.a) != test.wantReached {
+			t.Errorf("%d: got mode %q reached %d, want %q %d", i, test.a.Mode, reached(test.a), test.wantMode, test.wantReached)
+		}
+	}
+
+	// 转换为 set 后可以与 set 合并
+	p := pkg(ModeCount, 3)
+	if err := p.SetMode(ModeSet); err != nil || reached(p) != 1 {
+		t.Errorf("SetMode(set) = %v, reached %d", err, reached(p))
+	}
+	if err := pkg(ModeSet, 1).SetMode(ModeCount); err == nil {
+		t.Error("SetMode(count) of set data should fail")
+	}
+}
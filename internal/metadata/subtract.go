@@ -0,0 +1,61 @@
+package metadata
+
+// Subtract 从此 Package 的执行次数中减去 p2 中对应的执行次数，得到两次快照之间新增的执行情况。
+// p2 需要与此 Package 来自同一份代码，函数一一对应。
+// 计数比 p2 中小的计数器在两次快照之间被清零过，按清零后的计数(即此 Package 中的计数)统计，
+// 不会把清零后执行过的代码当作没有执行。
+// set 模式的计数只有 0 和 1，相减后只能看出快照之间首次执行的代码，需要 count 或 atomic 模式。
+func (p *Package) Subtract(p2 *Package) error {
+	neg := &Package{Name: p2.Name, Functions: make([]*Function, 0, len(p2.Functions))}
+	for _, f := range p2.Functions {
+		neg.Functions = append(neg.Functions, f.negated())
+	}
+	if err := p.Accumulate(neg); err != nil {
+		return err
+	}
+	for i, f := range p.Functions {
+		f.restoreResets(p2.Functions[i])
+	}
+	return nil
+}
+
+// negated 返回执行次数取反的副本，只包含 Accumulate 需要的字段
+func (f *Function) negated() *Function {
+	rv := &Function{Name: f.Name, File: f.File, Start: f.Start, End: f.End}
+	for _, s := range f.Statements {
+		rv.Statements = append(rv.Statements, &Statement{Start: s.Start, End: s.End, Reached: -s.Reached})
+	}
+	for _, b := range f.Blocks {
+		rv.Blocks = append(rv.Blocks, &Block{StartLine: b.StartLine, StartCol: b.StartCol, EndLine: b.EndLine, EndCol: b.EndCol, Count: -b.Count})
+	}
+	for _, b := range f.Branches {
+		nb := &Branch{Kind: b.Kind, Line: b.Line}
+		for _, o := range b.Outcomes {
+			nb.Outcomes = append(nb.Outcomes, &Outcome{Count: -o.Count})
+		}
+		rv.Branches = append(rv.Branches, nb)
+	}
+	return rv
+}
+
+// restoreResets 处理相减后为负的执行次数。计数只会增长，变小说明两次快照之间计数器被清零过，
+// 此时新快照的计数(即差值加回旧计数)就是清零后的执行次数。old 是与 f 一一对应的旧快照中的函数。
+func (f *Function) restoreResets(old *Function) {
+	for i, s := range f.Statements {
+		if s.Reached < 0 {
+			s.Reached += old.Statements[i].Reached
+		}
+	}
+	for i, b := range f.Blocks {
+		if b.Count < 0 {
+			b.Count += old.Blocks[i].Count
+		}
+	}
+	for i, b := range f.Branches {
+		for j, o := range b.Outcomes {
+			if o.Count < 0 {
+				o.Count += old.Branches[i].Outcomes[j].Count
+			}
+		}
+	}
+}
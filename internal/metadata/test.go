@@ -0,0 +1,86 @@
+package metadata
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// TestID 返回测试的标识：包的导入路径与测试函数名以 . 连接，如 example.com/calc.TestAbs
+func TestID(pkg, name string) string {
+	return pkg + "." + name
+}
+
+// SplitTestID 将测试标识拆分为包的导入路径与测试函数名
+func SplitTestID(id string) (pkg, name string) {
+	i := strings.LastIndex(id, ".")
+	if i < 0 {
+		return "", id
+	}
+	return id[:i], id[i+1:]
+}
+
+// ParseTestList 解析 go test -list 的输出，返回测试标识(见 TestID)。
+// 每个包先输出测试名，再输出一行 "ok <包> <耗时>"；没有测试文件的包只输出一行 "? <包> [no test files]"。
+func ParseTestList(r io.Reader) ([]string, error) {
+	var tests, names []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+		case len(fields) >= 2 && (fields[0] == "ok" || fields[0] == "?"):
+			for _, name := range names {
+				tests = append(tests, TestID(fields[1], name))
+			}
+			names = names[:0]
+		case len(fields) == 1 && strings.HasPrefix(fields[0], "Test"):
+			names = append(names, fields[0])
+		}
+	}
+	return tests, scanner.Err()
+}
+
+// Tests 返回执行过函数中任一覆盖块的测试(有序)
+func (f *Function) Tests() []string {
+	set := make(map[string]struct{})
+	for _, b := range f.Blocks {
+		for _, t := range b.Tests {
+			set[t] = struct{}{}
+		}
+	}
+	return sortedKeys(set)
+}
+
+// LineTests 返回函数中每行被哪些测试执行过(有序)
+func (f *Function) LineTests() map[int][]string {
+	sets := make(map[int]map[string]struct{})
+	for _, b := range f.Blocks {
+		if len(b.Tests) == 0 {
+			continue
+		}
+		for line := b.StartLine; line <= b.LastLine(); line++ {
+			if sets[line] == nil {
+				sets[line] = make(map[string]struct{})
+			}
+			for _, t := range b.Tests {
+				sets[line][t] = struct{}{}
+			}
+		}
+	}
+	rv := make(map[int][]string, len(sets))
+	for line, set := range sets {
+		rv[line] = sortedKeys(set)
+	}
+	return rv
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,59 @@
+package metadata
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitTestID(t *testing.T) {
+	pkg, name := SplitTestID(TestID("example.com/a.b/calc", "TestAbs"))
+	if pkg != "example.com/a.b/calc" || name != "TestAbs" {
+		t.Errorf("SplitTestID() = %q, %q", pkg, name)
+	}
+}
+
+func TestLineTests(t *testing.T) {
+	b1 := &Block{StartLine: 1, StartCol: 10, EndLine: 3, EndCol: 1}
+	b2 := &Block{StartLine: 3, StartCol: 2, EndLine: 4, EndCol: 2}
+	b1.AddTest("p.TestB")
+	b1.AddTest("p.TestA")
+	b1.AddTest("p.TestB")
+	b2.AddTest("p.TestC")
+	f := &Function{Blocks: []*Block{b1, b2}}
+
+	if want := []string{"p.TestA", "p.TestB"}; !reflect.DeepEqual(b1.Tests, want) {
+		t.Errorf("Block.Tests = %v, want %v", b1.Tests, want)
+	}
+	if want := []string{"p.TestA", "p.TestB", "p.TestC"}; !reflect.DeepEqual(f.Tests(), want) {
+		t.Errorf("Tests() = %v, want %v", f.Tests(), want)
+	}
+	want := map[int][]string{
+		1: {"p.TestA", "p.TestB"},
+		2: {"p.TestA", "p.TestB"},
+		3: {"p.TestC"},
+		4: {"p.TestC"},
+	}
+	if got := f.LineTests(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LineTests() = %v, want %v", got, want)
+	}
+}
+
+func TestParseTestList(t *testing.T) {
+	// go test -list '^Test' ./... 的输出：b 没有测试文件，c 有测试文件但没有测试函数
+	output := "TestA\n" +
+		"TestA2\n" +
+		"ok  \texample.com/ls/a\t0.002s\n" +
+		"?   \texample.com/ls/b\t[no test files]\n" +
+		"ok  \texample.com/ls/c\t(cached)\n" +
+		"TestD\n" +
+		"ok  \texample.com/ls/d\t0.001s\n"
+	got, err := ParseTestList(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"example.com/ls/a.TestA", "example.com/ls/a.TestA2", "example.com/ls/d.TestD"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTestList() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+// Package profile 将覆盖率数据写回 go test -coverprofile 的文本格式
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// DefaultMode 是数据中没有记录覆盖率模式时使用的模式
+const DefaultMode = metadata.ModeSet
+
+// line 是 profile 中的一行
+type line struct {
+	file  string
+	block *metadata.Block
+}
+
+// Write 将覆盖率数据中的原始覆盖块以 profile 格式输出到 w，可交给 go tool cover 等工具使用。
+// mode 为空时使用数据记录的覆盖率模式；set 模式下执行次数只输出 0 或 1。
+func Write(w io.Writer, ps utils.Packages, mode string) error {
+	if mode == "" {
+		mode = ps.Mode()
+	}
+	if mode == "" {
+		mode = DefaultMode
+	}
+
+	var lines []line
+	for _, p := range ps {
+		for _, f := range p.Functions {
+			if len(f.Blocks) == 0 && len(f.Statements) > 0 {
+				return fmt.Errorf("function %s in %s has no raw blocks, convert the profile again to write it back", f.Name, f.File)
+			}
+			// profile 中的文件名是 包导入路径/文件名
+			file := p.Name + "/" + filepath.Base(f.File)
+			for _, b := range f.Blocks {
+				lines = append(lines, line{file: file, block: b})
+			}
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if a.file != b.file {
+			return a.file < b.file
+		}
+		if a.block.StartLine != b.block.StartLine {
+			return a.block.StartLine < b.block.StartLine
+		}
+		return a.block.StartCol < b.block.StartCol
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "mode: %s\n", mode)
+	for _, l := range lines {
+		b := l.block
+		count := b.Count
+		if mode == metadata.ModeSet && count > 1 {
+			count = 1
+		}
+		fmt.Fprintf(bw, "%s:%d.%d,%d.%d %d %d\n", l.file, b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, count)
+	}
+	return bw.Flush()
+}
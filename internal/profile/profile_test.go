@@ -0,0 +1,75 @@
+package profile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/convert"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+const source = `package calc
+
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+`
+
+// TestWriteRoundTrip 转换 profile 后写回，结果应与原 profile 一致
+func TestWriteRoundTrip(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := filepath.Rel(wd, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "calc.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := "./" + filepath.ToSlash(dir)
+	want := "mode: count\n" +
+		pkg + "/calc.go:4.2,4.11 1 3\n" +
+		pkg + "/calc.go:4.11,6.3 1 1\n" +
+		pkg + "/calc.go:7.2,7.10 1 2\n"
+	in := filepath.Join(dir, "c.out")
+	if err = ioutil.WriteFile(in, []byte(want), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ps, _, err := convert.Do(in, &convert.Param{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = Write(&buf, ps, ""); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// set 模式下执行次数只输出 0 或 1
+	buf.Reset()
+	if err = Write(&buf, ps, "set"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("/calc.go:7.2,7.10 1 1\n")) {
+		t.Errorf("Write() in set mode =\n%s", buf.String())
+	}
+}
+
+func TestWriteWithoutBlocks(t *testing.T) {
+	f := &metadata.Function{Name: "f", Statements: []*metadata.Statement{{}}}
+	if err := Write(&bytes.Buffer{}, utils.Packages{{Name: "a", Functions: []*metadata.Function{f}}}, ""); err == nil {
+		t.Error("Write() without raw blocks should fail")
+	}
+}
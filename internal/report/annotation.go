@@ -0,0 +1,208 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamber92/go-cover/internal/gate"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// 未覆盖新代码行的规则。增量覆盖率未达到门禁阈值时使用 ruleBelowThreshold，级别为 error，否则为 warning
+const (
+	ruleUncovered      = "uncovered-new-lines"
+	ruleBelowThreshold = "diff-coverage-below-threshold"
+)
+
+// AnnotationParam 是输出代码注解的参数
+type AnnotationParam struct {
+	// Root 是注解中文件路径相对的项目根目录
+	Root string
+	// Gate 是增量覆盖率门禁的判定结果，为空表示未设置阈值
+	Gate *gate.Result
+}
+
+// annotation 是一段连续的未被执行的新代码行
+type annotation struct {
+	path     string
+	function string
+	lines    lineRange
+}
+
+// level 返回注解的规则与级别
+func (p *AnnotationParam) level() (rule, level string) {
+	if p.Gate != nil && !p.Gate.Passed {
+		return ruleBelowThreshold, "error"
+	}
+	return ruleUncovered, "warning"
+}
+
+// message 返回注解的内容
+func (p *AnnotationParam) message(a *annotation) string {
+	msg := fmt.Sprintf("Line %d of %s is not covered by tests.", a.lines.start, a.function)
+	if a.lines.start != a.lines.end {
+		msg = fmt.Sprintf("Lines %s of %s are not covered by tests.", formatRange(a.lines), a.function)
+	}
+	if p.Gate != nil && !p.Gate.Passed {
+		msg += fmt.Sprintf(" Diff %s coverage %.2f%% is below the required %.2f%%.",
+			p.Gate.Rule.Metric, p.Gate.Coverage.Percent(), p.Gate.Rule.Min)
+	}
+	return msg
+}
+
+// annotations 收集增量数据中每段未被执行的新代码行
+func annotations(ps utils.Packages, root string) ([]*annotation, error) {
+	var rv []*annotation
+	for _, p := range ps {
+		for _, f := range p.Functions {
+			ranges := lineRanges(f.MissedLines())
+			if len(ranges) == 0 {
+				continue
+			}
+			path, err := relativePath(root, f.File)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range ranges {
+				rv = append(rv, &annotation{path: path, function: f.Name, lines: r})
+			}
+		}
+	}
+	return rv, nil
+}
+
+// relativePath 返回 file 相对 root 的路径，以 / 分隔
+func relativePath(root, file string) (string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.Rel(root, file)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the path relative to %s. err: %w", root, err)
+	}
+	return filepath.ToSlash(path), nil
+}
+
+// WriteGitHubAnnotations 将增量数据中每段未被执行的新代码行输出为 GitHub Actions 的
+// ::warning / ::error 工作流命令，在 PR 的代码差异上显示为注解
+func WriteGitHubAnnotations(w io.Writer, ps utils.Packages, param *AnnotationParam) error {
+	as, err := annotations(ps, param.Root)
+	if err != nil {
+		return err
+	}
+	_, level := param.level()
+	for _, a := range as {
+		if _, err = fmt.Fprintf(w, "::%s file=%s,line=%d,endLine=%d,title=%s::%s\n", level,
+			escapeProperty(a.path), a.lines.start, a.lines.end, "Uncovered new lines", escapeData(param.message(a))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData 按工作流命令的规则转义消息
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty 按工作流命令的规则转义属性值
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// SARIF 2.1.0 中用到的部分结构
+type (
+	sarifLog struct {
+		Schema  string      `json:"$schema"`
+		Version string      `json:"version"`
+		Runs    []*sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool      `json:"tool"`
+		Results []*sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string       `json:"name"`
+		InformationURI string       `json:"informationUri"`
+		Rules          []*sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID                   string            `json:"id"`
+		ShortDescription     sarifMessage      `json:"shortDescription"`
+		DefaultConfiguration sarifRuleDefaults `json:"defaultConfiguration"`
+	}
+	sarifRuleDefaults struct {
+		Level string `json:"level"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string           `json:"ruleId"`
+		Level     string           `json:"level"`
+		Message   sarifMessage     `json:"message"`
+		Locations []*sarifLocation `json:"locations"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+		EndLine   int `json:"endLine"`
+	}
+)
+
+// WriteSARIF 将增量数据中每段未被执行的新代码行输出为 SARIF 2.1.0 格式，供代码扫描平台显示为注解
+func WriteSARIF(w io.Writer, ps utils.Packages, param *AnnotationParam) error {
+	as, err := annotations(ps, param.Root)
+	if err != nil {
+		return err
+	}
+	rule, level := param.level()
+	run := &sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "go-cover",
+			InformationURI: types.ProjectURL,
+			Rules: []*sarifRule{
+				{ID: ruleUncovered, ShortDescription: sarifMessage{"New lines are not covered by tests."}, DefaultConfiguration: sarifRuleDefaults{"warning"}},
+				{ID: ruleBelowThreshold, ShortDescription: sarifMessage{"New lines are not covered by tests, and the diff coverage is below the required threshold."}, DefaultConfiguration: sarifRuleDefaults{"error"}},
+			},
+		}},
+		Results: make([]*sarifResult, 0, len(as)),
+	}
+	for _, a := range as {
+		run.Results = append(run.Results, &sarifResult{
+			RuleID:  rule,
+			Level:   level,
+			Message: sarifMessage{param.message(a)},
+			Locations: []*sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: a.path, URIBaseID: "%SRCROOT%"},
+				Region:           sarifRegion{StartLine: a.lines.start, EndLine: a.lines.end},
+			}}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []*sarifRun{run},
+	})
+}
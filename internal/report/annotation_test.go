@@ -0,0 +1,66 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/gate"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func TestAnnotations(t *testing.T) {
+	root := t.TempDir()
+	f := &metadata.Function{
+		Name: "Max",
+		File: filepath.Join(root, "calc", "calc.go"),
+		Blocks: []*metadata.Block{
+			{StartLine: 33, StartCol: 2, EndLine: 33, EndCol: 11, NumStmt: 1, Count: 1},
+			{StartLine: 34, StartCol: 3, EndLine: 35, EndCol: 5, NumStmt: 1},
+			{StartLine: 36, StartCol: 2, EndLine: 36, EndCol: 10, NumStmt: 1},
+			{StartLine: 38, StartCol: 2, EndLine: 38, EndCol: 10, NumStmt: 1},
+		},
+		NewLineSet: map[int]struct{}{33: {}, 34: {}, 35: {}, 36: {}, 38: {}},
+	}
+	ps := utils.Packages{{Name: "calc", Functions: []*metadata.Function{f}}}
+
+	var buf bytes.Buffer
+	if err := WriteGitHubAnnotations(&buf, ps, &AnnotationParam{Root: root}); err != nil {
+		t.Fatal(err)
+	}
+	want := "::warning file=calc/calc.go,line=34,endLine=36,title=Uncovered new lines::Lines 34-36 of Max are not covered by tests.\n" +
+		"::warning file=calc/calc.go,line=38,endLine=38,title=Uncovered new lines::Line 38 of Max is not covered by tests.\n"
+	if buf.String() != want {
+		t.Errorf("WriteGitHubAnnotations() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// 增量覆盖率未达标时结果级别为 error
+	failed := &gate.Result{
+		Rule:     &gate.Rule{Name: gate.RuleDiff, Metric: metadata.MetricLine, Min: 80},
+		Coverage: metadata.Coverage{Reached: 1, Total: 5},
+	}
+	buf.Reset()
+	if err := WriteSARIF(&buf, ps, &AnnotationParam{Root: root, Gate: failed}); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	results := log.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	r := results[0]
+	if r.RuleID != ruleBelowThreshold || r.Level != "error" {
+		t.Errorf("result rule %s level %s, want %s error", r.RuleID, r.Level, ruleBelowThreshold)
+	}
+	if want := "Lines 34-36 of Max are not covered by tests. Diff line coverage 20.00% is below the required 80.00%."; r.Message.Text != want {
+		t.Errorf("message = %q, want %q", r.Message.Text, want)
+	}
+	if region := r.Locations[0].PhysicalLocation.Region; region.StartLine != 34 || region.EndLine != 36 {
+		t.Errorf("region = %+v, want 34-36", region)
+	}
+}
@@ -0,0 +1,223 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// coberturaCoverage 是 Cobertura XML 的根节点
+type coberturaCoverage struct {
+	XMLName         xml.Name            `xml:"coverage"`
+	LineRate        float64             `xml:"line-rate,attr"`
+	BranchRate      float64             `xml:"branch-rate,attr"`
+	LinesCovered    int                 `xml:"lines-covered,attr"`
+	LinesValid      int                 `xml:"lines-valid,attr"`
+	BranchesCovered int                 `xml:"branches-covered,attr"`
+	BranchesValid   int                 `xml:"branches-valid,attr"`
+	Complexity      float64             `xml:"complexity,attr"`
+	Version         string              `xml:"version,attr"`
+	Timestamp       int64               `xml:"timestamp,attr"`
+	Sources         []string            `xml:"sources>source"`
+	Packages        []*coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string            `xml:"name,attr"`
+	LineRate   float64           `xml:"line-rate,attr"`
+	BranchRate float64           `xml:"branch-rate,attr"`
+	Complexity float64           `xml:"complexity,attr"`
+	Classes    []*coberturaClass `xml:"classes>class"`
+}
+
+// coberturaClass 对应一个源文件
+type coberturaClass struct {
+	Name       string             `xml:"name,attr"`
+	Filename   string             `xml:"filename,attr"`
+	LineRate   float64            `xml:"line-rate,attr"`
+	BranchRate float64            `xml:"branch-rate,attr"`
+	Complexity float64            `xml:"complexity,attr"`
+	Methods    []*coberturaMethod `xml:"methods>method"`
+	Lines      []*coberturaLine   `xml:"lines>line"`
+}
+
+type coberturaMethod struct {
+	Name       string           `xml:"name,attr"`
+	Signature  string           `xml:"signature,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity float64          `xml:"complexity,attr"`
+	Lines      []*coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int64  `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+
+	branches metadata.Coverage // 判定点所在行的分支覆盖情况
+}
+
+// coberturaCounts 累计行、分支的覆盖情况与圈复杂度
+type coberturaCounts struct {
+	lines      metadata.Coverage
+	branches   metadata.Coverage
+	complexity int
+	methods    int
+}
+
+func (c *coberturaCounts) addLines(lines []*coberturaLine) {
+	for _, l := range lines {
+		c.lines.Total++
+		if l.Hits > 0 {
+			c.lines.Reached++
+		}
+		c.branches.Add(l.branches)
+	}
+}
+
+func (c *coberturaCounts) add(c2 *coberturaCounts) {
+	c.lines.Add(c2.lines)
+	c.branches.Add(c2.branches)
+	c.complexity += c2.complexity
+	c.methods += c2.methods
+}
+
+// averageComplexity 返回方法的平均圈复杂度
+func (c *coberturaCounts) averageComplexity() float64 {
+	if c.methods == 0 {
+		return 0
+	}
+	return float64(c.complexity) / float64(c.methods)
+}
+
+// rate 返回 0~1 之间的覆盖率，没有可统计的对象时为 1
+func rate(c metadata.Coverage) float64 {
+	if c.Total == 0 {
+		return 1
+	}
+	return float64(c.Reached) / float64(c.Total)
+}
+
+// WriteCobertura 将覆盖率数据以 Cobertura XML 格式输出到 w，每个源文件是一个 class，每个函数是一个 method。
+// 文件路径为相对 root 的路径；增量数据(NewLineSet 非空)只输出新代码行。
+// 判定点所在行标记为 branch，并以 condition-coverage 记录已执行的分支数，如 "50% (1/2)"。
+func WriteCobertura(w io.Writer, ps utils.Packages, root string) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	doc := &coberturaCoverage{Version: "go-cover", Timestamp: time.Now().UnixNano() / int64(time.Millisecond), Sources: []string{absRoot}}
+	var total coberturaCounts
+	for _, p := range ps {
+		pkg := &coberturaPackage{Name: p.Name}
+		var pkgCounts coberturaCounts
+		classes := make(map[string]*coberturaClass)
+		classCounts := make(map[string]*coberturaCounts)
+		for _, f := range p.Functions {
+			lines := coberturaLines(f)
+			if len(lines) == 0 {
+				continue
+			}
+			path, err := relativePath(root, f.File)
+			if err != nil {
+				return err
+			}
+			class := classes[path]
+			if class == nil {
+				class = &coberturaClass{Name: filepath.Base(path), Filename: path}
+				classes[path] = class
+				classCounts[path] = &coberturaCounts{}
+				pkg.Classes = append(pkg.Classes, class)
+			}
+			counts := &coberturaCounts{complexity: f.Cyclomatic, methods: 1}
+			counts.addLines(lines)
+			class.Methods = append(class.Methods, &coberturaMethod{
+				Name:       f.Name,
+				LineRate:   rate(counts.lines),
+				BranchRate: rate(counts.branches),
+				Complexity: float64(f.Cyclomatic),
+				Lines:      lines,
+			})
+			class.Lines = append(class.Lines, lines...)
+			classCounts[path].add(counts)
+		}
+		if len(pkg.Classes) == 0 {
+			continue
+		}
+		sort.Slice(pkg.Classes, func(i, j int) bool { return pkg.Classes[i].Filename < pkg.Classes[j].Filename })
+		for _, class := range pkg.Classes {
+			sort.SliceStable(class.Lines, func(i, j int) bool { return class.Lines[i].Number < class.Lines[j].Number })
+			counts := classCounts[class.Filename]
+			class.LineRate = rate(counts.lines)
+			class.BranchRate = rate(counts.branches)
+			class.Complexity = counts.averageComplexity()
+			pkgCounts.add(counts)
+		}
+		pkg.LineRate = rate(pkgCounts.lines)
+		pkg.BranchRate = rate(pkgCounts.branches)
+		pkg.Complexity = pkgCounts.averageComplexity()
+		doc.Packages = append(doc.Packages, pkg)
+		total.add(&pkgCounts)
+	}
+	doc.LineRate = rate(total.lines)
+	doc.BranchRate = rate(total.branches)
+	doc.LinesCovered, doc.LinesValid = total.lines.Reached, total.lines.Total
+	doc.BranchesCovered, doc.BranchesValid = total.branches.Reached, total.branches.Total
+	doc.Complexity = total.averageComplexity()
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// coberturaLines 返回函数中被插桩的代码行(升序)，执行次数取覆盖该行的块中的最大值。
+// 增量数据只返回新代码行。
+func coberturaLines(f *metadata.Function) []*coberturaLine {
+	lines := make(map[int]*coberturaLine)
+	for _, b := range f.Blocks {
+		for n := b.StartLine; n <= b.LastLine(); n++ {
+			if len(f.NewLineSet) > 0 {
+				if _, ok := f.NewLineSet[n]; !ok {
+					continue
+				}
+			}
+			if l, ok := lines[n]; !ok {
+				lines[n] = &coberturaLine{Number: n, Hits: b.Count}
+			} else if b.Count > l.Hits {
+				l.Hits = b.Count
+			}
+		}
+	}
+	for _, b := range f.Branches {
+		if l, ok := lines[b.Line]; ok {
+			l.branches.Total += len(b.Outcomes)
+			l.branches.Reached += b.Reached()
+		}
+	}
+
+	rv := make([]*coberturaLine, 0, len(lines))
+	for _, l := range lines {
+		if l.branches.Total > 0 {
+			l.Branch = true
+			l.ConditionCoverage = fmt.Sprintf("%d%% (%d/%d)", int(l.branches.Percent()), l.branches.Reached, l.branches.Total)
+		}
+		rv = append(rv, l)
+	}
+	sort.Slice(rv, func(i, j int) bool { return rv[i].Number < rv[j].Number })
+	return rv
+}
@@ -0,0 +1,63 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path/filepath"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func TestWriteCobertura(t *testing.T) {
+	root := t.TempDir()
+	f := &metadata.Function{
+		Name:       "Abs",
+		File:       filepath.Join(root, "calc", "calc.go"),
+		Cyclomatic: 2,
+		Blocks: []*metadata.Block{
+			{StartLine: 5, StartCol: 2, EndLine: 5, EndCol: 11, NumStmt: 1, Count: 3},
+			{StartLine: 6, StartCol: 3, EndLine: 7, EndCol: 1, NumStmt: 1, Count: 0},
+		},
+		Branches: []*metadata.Branch{
+			{Kind: "if", Line: 5, Outcomes: []*metadata.Outcome{{Label: "then", Count: 0}, {Label: "else", Count: 3}}},
+		},
+	}
+	ps := utils.Packages{{Name: "example.com/calc", Functions: []*metadata.Function{f}}}
+
+	var buf bytes.Buffer
+	if err := WriteCobertura(&buf, ps, root); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<line number="5" hits="3" branch="true" condition-coverage="50% (1/2)"></line>`,
+		`<line number="6" hits="0" branch="false"></line>`,
+		`<class name="calc.go" filename="calc/calc.go" line-rate="0.5" branch-rate="0.5" complexity="2">`,
+	} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("WriteCobertura() does not contain %s:\n%s", want, buf.String())
+		}
+	}
+	var doc coberturaCoverage
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.LinesCovered != 1 || doc.LinesValid != 2 || doc.BranchesCovered != 1 || doc.BranchesValid != 2 {
+		t.Errorf("WriteCobertura() totals = lines %d/%d, branches %d/%d, want 1/2 and 1/2",
+			doc.LinesCovered, doc.LinesValid, doc.BranchesCovered, doc.BranchesValid)
+	}
+	if len(doc.Packages) != 1 || len(doc.Packages[0].Classes) != 1 || len(doc.Packages[0].Classes[0].Methods) != 1 {
+		t.Fatalf("WriteCobertura() = %+v, want 1 package with 1 class and 1 method", doc.Packages)
+	}
+
+	// 增量数据只输出新代码行
+	f.NewLineSet = map[int]struct{}{6: {}}
+	buf.Reset()
+	if err := WriteCobertura(&buf, ps, root); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`number="5"`)) {
+		t.Errorf("WriteCobertura() of diff data contains old lines:\n%s", buf.String())
+	}
+}
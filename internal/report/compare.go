@@ -0,0 +1,159 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/compare"
+	"github.com/lamber92/go-cover/internal/report/themes"
+	"github.com/lamber92/go-cover/internal/report/types"
+)
+
+// WriteCompareText 以纯文本格式输出两份覆盖率数据的对比结果，只列出有变化的包和函数
+func WriteCompareText(w io.Writer, r *compare.Result) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tOLD\tNEW\tDELTA\tNEWLY UNCOVERED LINES")
+	for _, p := range r.Packages {
+		if p.Status == compare.StatusUnchanged {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%+.2f%%\t\n", p.Name, p.Status, formatCoverage(p.Old), formatCoverage(p.New), p.Delta())
+		for _, f := range p.Changed() {
+			fmt.Fprintf(tw, "  %s (%s)\t%s\t%s\t%s\t%+.2f%%\t%s\n",
+				f.Name, filepath.Base(f.File), f.Status, formatCoverage(f.Old), formatCoverage(f.New), f.Delta(), formatLines(f.NewlyUncovered))
+		}
+	}
+	fmt.Fprintf(tw, "TOTAL (%s)\t\t%s\t%s\t%+.2f%%\t\n", r.Metric, formatCoverage(r.Old), formatCoverage(r.New), r.Delta())
+	return tw.Flush()
+}
+
+// WriteCompareMarkdown 以 Markdown 格式输出两份覆盖率数据的对比结果，只列出有变化的包和函数
+func WriteCompareMarkdown(w io.Writer, r *compare.Result) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Coverage Delta (%s)\n\n", r.Metric)
+	fmt.Fprintf(&b, "Total: %s → %s (**%+.2f%%**)\n\n", formatCoverage(r.Old), formatCoverage(r.New), r.Delta())
+	b.WriteString("| Package | Status | Old | New | Delta |\n")
+	b.WriteString("| --- | --- | ---: | ---: | ---: |\n")
+	for _, p := range r.Packages {
+		if p.Status != compare.StatusUnchanged {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %+.2f%% |\n",
+				markdownEscape(p.Name), p.Status, formatCoverage(p.Old), formatCoverage(p.New), p.Delta())
+		}
+	}
+	for _, p := range r.Packages {
+		changed := p.Changed()
+		if len(changed) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", markdownEscape(p.Name))
+		b.WriteString("| Function | File | Status | Old | New | Delta | Newly Uncovered Lines |\n")
+		b.WriteString("| --- | --- | --- | ---: | ---: | ---: | --- |\n")
+		for _, f := range changed {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %+.2f%% | %s |\n",
+				f.Name, markdownEscape(filepath.Base(f.File)), f.Status, formatCoverage(f.Old), formatCoverage(f.New), f.Delta(), formatLines(f.NewlyUncovered))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteCompareHTML 输出两份覆盖率数据对比结果的 HTML 页面
+func WriteCompareHTML(w io.Writer, r *compare.Result) error {
+	data := struct {
+		CSS        string
+		When       string
+		ProjectURL string
+		*compare.Result
+	}{
+		CSS:        themes.Current().Data().CSS,
+		When:       time.Now().Format(time.RFC822Z),
+		ProjectURL: types.ProjectURL,
+		Result:     r,
+	}
+	if err := compareTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("execute template. err: %w", err)
+	}
+	return nil
+}
+
+// formatLines 将有序的行号格式化为区间，如 "3-5, 9"
+func formatLines(lines []int) string {
+	ranges := lineRanges(lines)
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = formatRange(r)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// deltaClass 按变化量返回样式名
+func deltaClass(delta float64) string {
+	switch {
+	case delta < 0:
+		return "down"
+	case delta > 0:
+		return "up"
+	}
+	return ""
+}
+
+var compareTemplate = template.Must(template.New("compare").Funcs(template.FuncMap{
+	"coverage": formatCoverage,
+	"lines":    formatLines,
+	"class":    deltaClass,
+	"base":     filepath.Base,
+}).Parse(`<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Coverage Delta</title>
+		{{.CSS}}
+		<style type="text/css">
+		    td.down { color: #a94442; }
+		    td.up { color: #3c763d; }
+		    tr.added td, tr.removed td { font-style: italic; }
+		</style>
+	</head>
+	<body>
+		<div id="doctitle">Coverage Delta</div>
+        <div id="about">Generated on {{.When}} with <a href="{{.ProjectURL}}">go-cover</a></div>
+        <div class="funcname">
+            Overview ({{.Metric}})
+            <span class="packageTotal">{{coverage .Old}} → {{coverage .New}} ({{printf "%+.2f%%" .Delta}})</span>
+        </div>
+        <table class="overview">
+        {{range $k,$p := .Packages}}
+            <tr class="{{$p.Status}}">
+                <td><code>{{if $p.Changed}}<a href="#pkg_{{html $p.Name}}">{{html $p.Name}}</a>{{else}}{{html $p.Name}}{{end}}</code></td>
+                <td><code>{{$p.Status}}</code></td>
+                <td class="linecount"><code>{{coverage $p.Old}}</code></td>
+                <td class="linecount"><code>{{coverage $p.New}}</code></td>
+                <td class="percent {{class $p.Delta}}"><code>{{printf "%+.2f%%" $p.Delta}}</code></td>
+            </tr>
+        {{end}}
+        </table>
+        {{range $k,$p := .Packages}}
+        {{if $p.Changed}}
+        <div id="pkg_{{html $p.Name}}" class="funcname">Package: {{html $p.Name}}</div>
+        <table class="overview">
+        {{range $i,$f := $p.Changed}}
+            <tr class="{{$f.Status}}">
+                <td><code>{{html $f.Name}}(...)</code></td>
+                <td><code>{{html (base $f.File)}}</code></td>
+                <td><code>{{$f.Status}}</code></td>
+                <td class="linecount"><code>{{coverage $f.Old}}</code></td>
+                <td class="linecount"><code>{{coverage $f.New}}</code></td>
+                <td class="percent {{class $f.Delta}}"><code>{{printf "%+.2f%%" $f.Delta}}</code></td>
+                <td class="down"><code>{{if $f.NewlyUncovered}}newly uncovered lines: {{lines $f.NewlyUncovered}}{{end}}</code></td>
+            </tr>
+        {{end}}
+        </table>
+        {{end}}
+        {{end}} {{/* range packages end */}}
+	</body>
+</html>
+`))
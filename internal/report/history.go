@@ -0,0 +1,244 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/history"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/themes"
+	"github.com/lamber92/go-cover/internal/report/types"
+)
+
+// WriteHistoryText 以纯文本格式输出覆盖率历史，以及最新快照相对上一次快照的覆盖率下降项
+func WriteHistoryText(w io.Writer, snaps []*history.Snapshot) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tCOMMIT\tBRANCH\tMETRIC\tTOTAL\tDIFF\tCHANGE")
+	for i, s := range snaps {
+		diff, change := "-", "-"
+		if s.Diff != nil {
+			diff = formatCoverage(*s.Diff)
+		}
+		if i > 0 {
+			change = fmt.Sprintf("%+.2f%%", s.Total.Percent()-snaps[i-1].Total.Percent())
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Time.Local().Format(time.RFC3339), shortCommit(s.Commit), s.Branch, s.Metric, formatCoverage(s.Total), diff, change)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	regressions := latestRegressions(snaps)
+	if len(regressions) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "\nREGRESSIONS AGAINST THE PREVIOUS SNAPSHOT")
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPREVIOUS\tCURRENT\tCHANGE")
+	for _, c := range regressions {
+		fmt.Fprintf(tw, "%s\t%.2f%%\t%.2f%%\t%+.2f%%\n", c.Name, c.Previous, c.Current, c.Delta())
+	}
+	return tw.Flush()
+}
+
+// WriteHistoryHTML 输出覆盖率历史的 HTML 页面，包含全量、增量以及每个包的趋势图
+func WriteHistoryHTML(w io.Writer, snaps []*history.Snapshot) error {
+	data := &historyData{
+		CSS:         themes.Current().Data().CSS,
+		When:        time.Now().Format(time.RFC822Z),
+		ProjectURL:  types.ProjectURL,
+		Snapshots:   snaps,
+		Regressions: latestRegressions(snaps),
+	}
+	if len(snaps) > 0 {
+		data.Total = trendChart([]trendSeries{
+			{Name: history.NameTotal, Color: "#375eab", Points: pointsOf(snaps, func(s *history.Snapshot) *metadata.Coverage { return &s.Total })},
+			{Name: history.NameDiff, Color: "#3c763d", Points: pointsOf(snaps, func(s *history.Snapshot) *metadata.Coverage { return s.Diff })},
+		})
+		for _, name := range packageNames(snaps) {
+			name := name
+			data.Packages = append(data.Packages, packageTrend{
+				Name: name,
+				Chart: trendChart([]trendSeries{{Name: name, Color: "#375eab", Points: pointsOf(snaps, func(s *history.Snapshot) *metadata.Coverage {
+					if c, ok := s.Packages[name]; ok {
+						return &c
+					}
+					return nil
+				})}}),
+			})
+		}
+	}
+	if err := historyTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("execute template. err: %w", err)
+	}
+	return nil
+}
+
+// latestRegressions 返回最新快照相对上一次快照的覆盖率下降项
+func latestRegressions(snaps []*history.Snapshot) []*history.Change {
+	if len(snaps) < 2 {
+		return nil
+	}
+	return history.Regressions(snaps[len(snaps)-2], snaps[len(snaps)-1])
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
+
+// packageNames 返回所有快照中出现过的包名
+func packageNames(snaps []*history.Snapshot) []string {
+	set := make(map[string]struct{})
+	for _, s := range snaps {
+		for name := range s.Packages {
+			set[name] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type historyData struct {
+	CSS         string
+	When        string
+	ProjectURL  string
+	Snapshots   []*history.Snapshot
+	Regressions []*history.Change
+	Total       string
+	Packages    []packageTrend
+}
+
+type packageTrend struct {
+	Name  string
+	Chart string
+}
+
+// trendSeries 是趋势图中的一条折线，Points 中 nil 表示该快照没有数据
+type trendSeries struct {
+	Name   string
+	Color  string
+	Points []*float64
+}
+
+func pointsOf(snaps []*history.Snapshot, get func(*history.Snapshot) *metadata.Coverage) []*float64 {
+	points := make([]*float64, len(snaps))
+	for i, s := range snaps {
+		if c := get(s); c != nil {
+			percent := c.Percent()
+			points[i] = &percent
+		}
+	}
+	return points
+}
+
+const (
+	chartWidth   = 600
+	chartHeight  = 160
+	chartPadding = 30
+)
+
+// trendChart 生成覆盖率趋势的 SVG 折线图，纵轴为 0-100%，横轴为快照序号
+func trendChart(series []trendSeries) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" class="trend">`, chartWidth, chartHeight)
+	plotWidth := float64(chartWidth - 2*chartPadding)
+	plotHeight := float64(chartHeight - 2*chartPadding)
+	for _, percent := range []int{0, 50, 100} {
+		y := chartPadding + plotHeight*(1-float64(percent)/100)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ddd"/>`, chartPadding, y, chartWidth-chartPadding, y)
+		fmt.Fprintf(&b, `<text x="2" y="%.1f" font-size="10">%d%%</text>`, y+3, percent)
+	}
+	for i, s := range series {
+		var points []string
+		for j, p := range s.Points {
+			if p == nil {
+				continue
+			}
+			x := float64(chartPadding)
+			if len(s.Points) > 1 {
+				x += plotWidth * float64(j) / float64(len(s.Points)-1)
+			}
+			y := chartPadding + plotHeight*(1-*p/100)
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"><title>%s %.2f%%</title></circle>`, x, y, s.Color, html.EscapeString(s.Name), *p)
+		}
+		if len(points) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, s.Color, strings.Join(points, " "))
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="11" fill="%s">%s</text>`, chartPadding+i*120, chartHeight-8, s.Color, html.EscapeString(s.Name))
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+var historyTemplate = template.Must(template.New("history").Funcs(template.FuncMap{
+	"coverage": formatCoverage,
+	"commit":   shortCommit,
+	"time":     func(t time.Time) string { return t.Local().Format(time.RFC3339) },
+}).Parse(`<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Coverage History</title>
+		{{.CSS}}
+		<style type="text/css">
+		    tr.regression td { color: #a94442; }
+		    svg.trend { margin-left: 10px; }
+		</style>
+	</head>
+	<body>
+		<div id="doctitle">Coverage History</div>
+        <div id="about">Generated on {{.When}} with <a href="{{.ProjectURL}}">go-cover</a></div>
+        {{if not .Snapshots}}
+        <p>no snapshots in history.</p>
+        {{else}}
+        {{if .Regressions}}
+        <div class="funcname">Regressions Against The Previous Snapshot</div>
+        <table class="overview">
+        {{range $k,$c := .Regressions}}
+            <tr class="regression">
+                <td><code>{{html $c.Name}}</code></td>
+                <td class="percent"><code>{{printf "%.2f%%" $c.Previous}}</code></td>
+                <td class="percent"><code>{{printf "%.2f%%" $c.Current}}</code></td>
+                <td class="percent"><code>{{printf "%+.2f%%" $c.Delta}}</code></td>
+            </tr>
+        {{end}}
+        </table>
+        {{end}} {{/* if regressions end */}}
+        <div class="funcname">Total And Diff Coverage</div>
+        <p>{{.Total}}</p>
+        <div class="funcname">Package Coverage</div>
+        {{range $k,$p := .Packages}}
+        <p><code>{{html $p.Name}}</code><br>{{$p.Chart}}</p>
+        {{end}}
+        <div class="funcname">Snapshots</div>
+        <table class="overview">
+        {{range $k,$s := .Snapshots}}
+            <tr>
+                <td><code>{{time $s.Time}}</code></td>
+                <td><code>{{html (commit $s.Commit)}}</code></td>
+                <td><code>{{html $s.Branch}}</code></td>
+                <td><code>{{$s.Metric}}</code></td>
+                <td class="linecount"><code>total {{coverage $s.Total}}</code></td>
+                <td class="linecount"><code>{{if $s.Diff}}diff {{coverage $s.Diff}}{{end}}</code></td>
+            </tr>
+        {{end}}
+        </table>
+        {{end}} {{/* if snapshots end */}}
+	</body>
+</html>
+`))
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/history"
+	"github.com/lamber92/go-cover/internal/metadata"
+)
+
+// historySnapshots 返回两次快照，第二次的全量与包 example.com/p 的覆盖率下降
+func historySnapshots() []*history.Snapshot {
+	now := time.Now()
+	return []*history.Snapshot{
+		{
+			Time:     now.Add(-time.Hour),
+			Commit:   "0123456789abcdef",
+			Branch:   "feat/<x>",
+			Metric:   metadata.MetricStatement,
+			Total:    metadata.Coverage{Reached: 8, Total: 10},
+			Packages: map[string]metadata.Coverage{"example.com/p": {Reached: 8, Total: 10}},
+		},
+		{
+			Time:     now,
+			Commit:   "fedcba9876543210",
+			Branch:   "feat/<x>",
+			Metric:   metadata.MetricStatement,
+			Total:    metadata.Coverage{Reached: 6, Total: 10},
+			Diff:     &metadata.Coverage{Reached: 1, Total: 2},
+			Packages: map[string]metadata.Coverage{"example.com/p": {Reached: 6, Total: 10}},
+		},
+	}
+}
+
+func TestWriteHistoryText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHistoryText(&buf, historySnapshots()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"01234567", "fedcba98", "60.00% (6/10)", "50.00% (1/2)", "-20.00%", "REGRESSIONS AGAINST THE PREVIOUS SNAPSHOT"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteHistoryText() output does not contain %q:\n%s", want, out)
+		}
+	}
+	regressions := out[strings.Index(out, "REGRESSIONS"):]
+	for _, name := range []string{history.NameTotal, "example.com/p"} {
+		if !strings.Contains(regressions, name) {
+			t.Errorf("regressions do not contain %q:\n%s", name, regressions)
+		}
+	}
+
+	// 只有一次快照时没有可比较的上一次快照
+	buf.Reset()
+	if err := WriteHistoryText(&buf, historySnapshots()[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "REGRESSIONS") {
+		t.Errorf("WriteHistoryText() with one snapshot reports regressions:\n%s", buf.String())
+	}
+}
+
+func TestWriteHistoryHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHistoryHTML(&buf, historySnapshots()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Regressions Against The Previous Snapshot", "<svg", "<polyline", "example.com/p", "feat/&lt;x&gt;", "diff 50.00% (1/2)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteHistoryHTML() output does not contain %q", want)
+		}
+	}
+	if strings.Contains(out, "feat/<x>") {
+		t.Error("WriteHistoryHTML() output contains an unescaped branch name")
+	}
+
+	buf.Reset()
+	if err := WriteHistoryHTML(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "no snapshots in history.") {
+		t.Errorf("WriteHistoryHTML() without snapshots = %s", buf.String())
+	}
+}
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// WriteMarkdown 将覆盖率数据以 Markdown 格式输出到 w，适合作为 PR 评论或 CI 摘要。
+// 输出包含每个包的覆盖率汇总表、按 sortBy 排序的函数明细(折叠显示)以及风险最高的未完全覆盖函数。
+func WriteMarkdown(w io.Writer, ps utils.Packages, sortBy types.SortMethod) error {
+	rps, err := buildReportPackages(ps, nil, sortBy)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("## Coverage Report\n\n")
+	if mode := ps.Mode(); mode != "" {
+		fmt.Fprintf(&b, "Cover mode: `%s`\n\n", mode)
+	}
+	b.WriteString("| Package | Statements | Lines | Blocks | Branches |\n")
+	b.WriteString("| --- | ---: | ---: | ---: | ---: |\n")
+	var total [4]metadata.Coverage
+	for _, rp := range rps {
+		pkgCoverage := packageCoverages(rp)
+		for i := range total {
+			total[i].Add(pkgCoverage[i])
+		}
+		writeMarkdownRow(&b, markdownEscape(rp.Pkg.Name), pkgCoverage)
+	}
+	writeMarkdownRow(&b, "**Total**", total)
+
+	for _, rp := range rps {
+		if len(rp.Functions) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n<details><summary>%s</summary>\n\n", html.EscapeString(rp.Pkg.Name))
+		b.WriteString("| Function | File | Statements | Lines | Blocks | Branches | CRAP |\n")
+		b.WriteString("| --- | --- | ---: | ---: | ---: | ---: | ---: |\n")
+		for _, fn := range rp.Functions {
+			fmt.Fprintf(&b, "| `%s` | %s:%d", fn.Name, markdownEscape(fn.ShortFileName()), fn.StartLine)
+			for _, c := range functionCoverages(fn) {
+				b.WriteString(" | " + formatCoverage(c))
+			}
+			fmt.Fprintf(&b, " | %.1f |\n", fn.CRAP())
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	if riskiest := types.Riskiest(rps, riskiestCount); len(riskiest) > 0 {
+		b.WriteString("\n### Riskiest Uncovered Functions\n\n")
+		b.WriteString("| Function | File | Coverage | Cyclomatic | Cognitive | CRAP |\n")
+		b.WriteString("| --- | --- | ---: | ---: | ---: | ---: |\n")
+		for _, f := range riskiest {
+			fmt.Fprintf(&b, "| `%s` | %s/%s:%d | %.2f%% | %d | %d | %.1f |\n",
+				f.Name, markdownEscape(f.Package), markdownEscape(f.ShortFileName()), f.StartLine,
+				f.CoveragePercent(), f.Cyclomatic, f.Cognitive, f.CRAP())
+		}
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownRow(b *strings.Builder, name string, cs [4]metadata.Coverage) {
+	b.WriteString("| " + name)
+	for _, c := range cs {
+		b.WriteString(" | " + formatCoverage(c))
+	}
+	b.WriteString(" |\n")
+}
+
+// markdownEscape 转义会破坏表格结构的字符
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "_", `\_`, "*", `\*`).Replace(s)
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func TestWriteMarkdownSort(t *testing.T) {
+	covered := &metadata.Function{Name: "Covered", File: "calc.go", Cyclomatic: 1, Statements: []*metadata.Statement{{Reached: 1}}}
+	risky := &metadata.Function{Name: "Risky", File: "calc.go", Cyclomatic: 4, Statements: []*metadata.Statement{{Reached: 0}}}
+	ps := utils.Packages{{Name: "calc", Functions: []*metadata.Function{covered, risky}}}
+
+	tests := []struct {
+		sortBy types.SortMethod
+		first  string
+	}{
+		{types.SortByCoverage, "Covered"},
+		{types.SortByCRAP, "Risky"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := WriteMarkdown(&buf, ps, tt.sortBy); err != nil {
+			t.Fatal(err)
+		}
+		details := buf.String()[strings.Index(buf.String(), "<details>"):]
+		if i, j := strings.Index(details, "`Covered`"), strings.Index(details, "`Risky`"); i < 0 || j < 0 || (i < j) != (tt.first == "Covered") {
+			t.Errorf("WriteMarkdown(%s) functions are not sorted, want %s first:\n%s", tt.sortBy, tt.first, details)
+		}
+	}
+}
+
+func TestWriteMarkdownMode(t *testing.T) {
+	ps := utils.Packages{{Name: "calc", Mode: metadata.ModeCount}}
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, ps, types.SortByCoverage); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Cover mode: `count`") {
+		t.Errorf("WriteMarkdown() header does not show the cover mode:\n%s", buf.String())
+	}
+}
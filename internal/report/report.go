@@ -2,8 +2,10 @@ package report
 
 import (
 	"fmt"
+	"io"
 	"os"
 
+	"github.com/lamber92/go-cover/internal/errs"
 	"github.com/lamber92/go-cover/internal/metadata"
 	"github.com/lamber92/go-cover/internal/report/types"
 	"github.com/lamber92/go-cover/internal/utils"
@@ -15,40 +17,63 @@ type GenerateHTMLParam struct {
 	Dir          string
 	FileName     string
 	BranchesInfo *metadata.BranchesInfo
+	// GitFallback 源文件已变更时，是否从转换时记录的提交点取回原始内容
+	GitFallback bool
+	// KeepGoing 源文件缺失时是否继续渲染其余内容，并在报告中列出被跳过的文件
+	KeepGoing bool
+	// Skipped 是转换阶段被跳过的文件，会在报告中列出
+	Skipped []*errs.SkippedFile
+	// Sort 是报告中函数列表的排序方式，缺省时按覆盖率从高到低排列
+	Sort types.SortMethod
 }
 
-// GenerateHTML 通过解析 go-convert/metadata 数据输出 HTML 报告。
+// GenerateHTML 通过解析 go-convert/metadata 数据输出 HTML 报告文件。
 // css 参数是自定义样式表的绝对路径。使用空字符串以使用可用的默认样式表。
 func GenerateHTML(param *GenerateHTMLParam) error {
+	file, err := utils.CreateFile(param.Dir, param.FileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return WriteHTML(file, param, param.FileName == utils.DiffHTML)
+}
+
+// WriteHTML 通过解析 go-convert/metadata 数据将 HTML 报告输出到 w，忽略 param 中的 Dir 和 FileName。
+// diff 为 true 时输出增量报告，否则输出全量报告。
+func WriteHTML(w io.Writer, param *GenerateHTMLParam, diff bool) error {
 	// Custom stylesheet?
 	stylesheet := ""
 	if param.CSS != "" {
 		if _, err := exists(param.CSS); err != nil {
-			return fmt.Errorf("stylesheet(css) is not exists. err: %v", err)
+			return fmt.Errorf("stylesheet(css) is not exists. err: %w", err)
 		}
 		stylesheet = param.CSS
 	}
 
+	branchesInfo := param.BranchesInfo
+	if branchesInfo == nil {
+		branchesInfo = &metadata.BranchesInfo{}
+	}
 	reporter := newReport(param.Packages,
 		stylesheet,
 		&types.BranchesInfo{
-			TargetBranchName:  param.BranchesInfo.TargetBranchName,
-			CurrentBranchName: param.BranchesInfo.CurrentBranchName,
-			StartHashID:       param.BranchesInfo.StartHashID,
-			EndHashID:         param.BranchesInfo.EndHashID,
-		})
-	file, err := utils.CreateFile(param.Dir, param.FileName)
-	if err != nil {
-		return err
-	}
+			TargetBranchName:  branchesInfo.TargetBranchName,
+			CurrentBranchName: branchesInfo.CurrentBranchName,
+			StartHashID:       branchesInfo.StartHashID,
+			EndHashID:         branchesInfo.EndHashID,
+		},
+		newSourceLoader(param.GitFallback, param.KeepGoing),
+		param.Skipped,
+		param.Sort)
 
-	if param.FileName == utils.DiffHTML {
-		if err = writeDiffReport(file, reporter); err != nil {
-			return fmt.Errorf("generate HTML diff-report failed. err: %v", err)
+	if diff {
+		if err := writeDiffReport(w, reporter); err != nil {
+			return fmt.Errorf("generate HTML diff-report failed. err: %w", err)
 		}
 	} else {
-		if err = writeFullReport(file, reporter); err != nil {
-			return fmt.Errorf("generate HTML full-report failed. err: %v", err)
+		if err := writeFullReport(w, reporter); err != nil {
+			return fmt.Errorf("generate HTML full-report failed. err: %w", err)
 		}
 	}
 
@@ -59,14 +84,20 @@ type report struct {
 	packages   utils.Packages
 	stylesheet string // absolute path to CSS
 	commit     *types.BranchesInfo
+	sources    *sourceLoader
+	skipped    []*errs.SkippedFile // 转换阶段被跳过的文件
+	sort       types.SortMethod    // 函数列表的排序方式
 }
 
 // newReport 创建一个新报表。
-func newReport(ps utils.Packages, stylesheet string, commit *types.BranchesInfo) (r *report) {
+func newReport(ps utils.Packages, stylesheet string, commit *types.BranchesInfo, sources *sourceLoader, skipped []*errs.SkippedFile, sort types.SortMethod) (r *report) {
 	r = &report{
 		packages:   ps,
 		stylesheet: stylesheet,
 		commit:     commit,
+		sources:    sources,
+		skipped:    skipped,
+		sort:       sort,
 	}
 	return
 }
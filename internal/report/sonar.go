@@ -0,0 +1,86 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// sonarCoverage 是 SonarQube 通用覆盖率格式(generic coverage)的根节点
+type sonarCoverage struct {
+	XMLName xml.Name     `xml:"coverage"`
+	Version int          `xml:"version,attr"`
+	Files   []*sonarFile `xml:"file"`
+}
+
+type sonarFile struct {
+	Path  string       `xml:"path,attr"`
+	Lines []*sonarLine `xml:"lineToCover"`
+}
+
+type sonarLine struct {
+	LineNumber      int  `xml:"lineNumber,attr"`
+	Covered         bool `xml:"covered,attr"`
+	BranchesToCover int  `xml:"branchesToCover,attr,omitempty"`
+	CoveredBranches int  `xml:"coveredBranches,attr,omitempty"`
+}
+
+// WriteSonar 将覆盖率数据以 SonarQube 通用覆盖率 XML 格式输出到 w。
+// 文件路径为相对 root 的路径；增量数据(NewLineSet 非空)只输出新代码行。
+// 判定点的分支数记在判定点所在行上。
+func WriteSonar(w io.Writer, ps utils.Packages, root string) error {
+	files := make(map[string]map[int]*sonarLine)
+	for _, p := range ps {
+		for _, f := range p.Functions {
+			path, err := relativePath(root, f.File)
+			if err != nil {
+				return err
+			}
+			if files[path] == nil {
+				files[path] = make(map[int]*sonarLine)
+			}
+			lines := files[path]
+			for line, hit := range f.LineHits() {
+				if len(f.NewLineSet) > 0 {
+					if _, ok := f.NewLineSet[line]; !ok {
+						continue
+					}
+				}
+				lines[line] = &sonarLine{LineNumber: line, Covered: hit}
+			}
+			for _, b := range f.Branches {
+				if l, ok := lines[b.Line]; ok {
+					l.BranchesToCover += len(b.Outcomes)
+					l.CoveredBranches += b.Reached()
+				}
+			}
+		}
+	}
+
+	doc := &sonarCoverage{Version: 1}
+	for path, lines := range files {
+		if len(lines) == 0 {
+			continue
+		}
+		file := &sonarFile{Path: path}
+		for _, l := range lines {
+			file.Lines = append(file.Lines, l)
+		}
+		sort.Slice(file.Lines, func(i, j int) bool { return file.Lines[i].LineNumber < file.Lines[j].LineNumber })
+		doc.Files = append(doc.Files, file)
+	}
+	sort.Slice(doc.Files, func(i, j int) bool { return doc.Files[i].Path < doc.Files[j].Path })
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func TestWriteSonar(t *testing.T) {
+	root := t.TempDir()
+	f := &metadata.Function{
+		Name: "Abs",
+		File: filepath.Join(root, "calc", "calc.go"),
+		Blocks: []*metadata.Block{
+			{StartLine: 5, StartCol: 2, EndLine: 5, EndCol: 11, NumStmt: 1, Count: 1},
+			{StartLine: 6, StartCol: 3, EndLine: 7, EndCol: 1, NumStmt: 1, Count: 0},
+		},
+		Branches: []*metadata.Branch{
+			{Kind: "if", Line: 5, Outcomes: []*metadata.Outcome{{Label: "then", Count: 0}, {Label: "else", Count: 1}}},
+		},
+	}
+	ps := utils.Packages{{Name: "calc", Functions: []*metadata.Function{f}}}
+
+	var buf bytes.Buffer
+	if err := WriteSonar(&buf, ps, root); err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage version="1">
+  <file path="calc/calc.go">
+    <lineToCover lineNumber="5" covered="true" branchesToCover="2" coveredBranches="1"></lineToCover>
+    <lineToCover lineNumber="6" covered="false"></lineToCover>
+  </file>
+</coverage>
+`
+	if buf.String() != want {
+		t.Errorf("WriteSonar() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// 增量数据只输出新代码行
+	f.NewLineSet = map[int]struct{}{6: {}}
+	buf.Reset()
+	if err := WriteSonar(&buf, ps, root); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`lineNumber="5"`)) {
+		t.Errorf("WriteSonar() of diff data contains old lines:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/lamber92/go-cover/internal/errs"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// sourceLoader 负责加载渲染报告所需的源文件内容，并校验其是否与转换时一致。
+type sourceLoader struct {
+	gitFallback bool // 源文件变更时是否尝试从 git 取回转换时的内容
+	keepGoing   bool // 源文件缺失时是否标记为过期并继续渲染
+	files       map[string]*types.SourceFile
+	skipped     []*errs.SkippedFile // 不可用(过期或缺失)的源文件
+}
+
+// newSourceLoader 创建源文件加载器
+func newSourceLoader(gitFallback, keepGoing bool) *sourceLoader {
+	return &sourceLoader{
+		gitFallback: gitFallback,
+		keepGoing:   keepGoing,
+		files:       make(map[string]*types.SourceFile),
+	}
+}
+
+// load 加载包中指定文件的内容，同一文件只加载一次
+func (l *sourceLoader) load(pkg *metadata.Package, file string) (*types.SourceFile, error) {
+	if sf, ok := l.files[file]; ok {
+		return sf, nil
+	}
+	sf, err := l.read(pkg.Source(file), file)
+	if err != nil {
+		if !l.keepGoing {
+			return nil, err
+		}
+		sf = types.NewStaleSourceFile(err.Error())
+	}
+	if sf.Stale {
+		l.skipped = append(l.skipped, &errs.SkippedFile{File: file, Reason: sf.Reason})
+	}
+	l.files[file] = sf
+	return sf, nil
+}
+
+// read 读取源文件内容。
+// 优先使用转换时嵌入的内容；否则读取磁盘文件，
+// 内容与转换时记录的摘要不一致(或文件缺失)时，按需从记录的提交点取回原始内容；
+// 仍无法还原时，内容不一致的文件标记为过期，缺失的文件返回 errs.MissingSourceError。
+func (l *sourceLoader) read(source *metadata.Source, file string) (*types.SourceFile, error) {
+	if source != nil && len(source.Content) > 0 {
+		data, err := utils.Decompress(source.Content)
+		if err != nil {
+			return nil, &errs.ParseError{File: file, Err: fmt.Errorf("embedded source is broken. err: %w", err)}
+		}
+		return types.NewSourceFile(data), nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err == nil && (source == nil || len(source.Hash) == 0 || utils.HashContent(data) == source.Hash) {
+		return types.NewSourceFile(data), nil
+	}
+
+	reason := "source file has changed since the profile was produced"
+	if l.gitFallback && source != nil && len(source.Commit) > 0 {
+		gitData, gitErr := utils.ReadFileAtCommit(source.Commit, file)
+		switch {
+		case gitErr != nil:
+			reason = fmt.Sprintf("%s; %v", reason, gitErr)
+		case utils.HashContent(gitData) != source.Hash:
+			reason = fmt.Sprintf("%s; content at commit[%s] does not match either", reason, source.Commit)
+		default:
+			return types.NewSourceFile(gitData), nil
+		}
+	}
+	if err != nil {
+		return nil, &errs.MissingSourceError{File: file, Err: err}
+	}
+	return types.NewStaleSourceFile(reason), nil
+}
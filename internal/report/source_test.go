@@ -0,0 +1,145 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/convert"
+	"github.com/lamber92/go-cover/internal/errs"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func TestSourceLoader(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.go")
+	content := []byte("package p\n\nfunc f() {\n\tprintln()\n}\n")
+	if err := ioutil.WriteFile(file, content, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	embedded, err := utils.Compress(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.go")
+
+	tests := []struct {
+		name      string
+		source    *metadata.Source
+		file      string
+		keepGoing bool
+		wantStale bool
+		wantErr   bool
+	}{
+		{"hash matches", &metadata.Source{File: file, Hash: utils.HashContent(content)}, file, false, false, false},
+		{"no hash recorded", nil, file, false, false, false},
+		{"hash mismatches", &metadata.Source{File: file, Hash: utils.HashContent([]byte("old"))}, file, false, true, false},
+		{"missing file", nil, missing, false, false, true},
+		{"missing file and keep going", nil, missing, true, true, false},
+		{"embedded source", &metadata.Source{File: missing, Hash: utils.HashContent(content), Content: embedded}, missing, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := &metadata.Package{Name: "p"}
+			pkg.AddSource(tt.source)
+			loader := newSourceLoader(false, tt.keepGoing)
+			sf, err := loader.load(pkg, tt.file)
+			if tt.wantErr {
+				var missingErr *errs.MissingSourceError
+				if !errors.As(err, &missingErr) {
+					t.Fatalf("load() err = %v, want MissingSourceError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sf.Stale != tt.wantStale {
+				t.Errorf("load() stale = %v, want %v (%s)", sf.Stale, tt.wantStale, sf.Reason)
+			}
+			if tt.wantStale && len(loader.skipped) != 1 {
+				t.Errorf("load() skipped = %d files, want 1", len(loader.skipped))
+			}
+		})
+	}
+}
+
+func TestLinesOutOfRange(t *testing.T) {
+	fn := types.ReportFunction{
+		Function: &metadata.Function{Name: "f", Start: 11, End: 100},
+		Source:   types.NewSourceFile([]byte("package p\n\nfunc f() {}\n")),
+	}
+	if !fn.Stale() {
+		t.Error("Expected out-of-range function to be stale")
+	}
+	if lines := fn.Lines(); lines != nil {
+		t.Errorf("Lines() = %v, want nil", lines)
+	}
+}
+
+// TestGitFallback 转换后修改源文件，开启 --git-source 时报告应展示转换时提交的内容，而不是将文件标记为过期
+func TestGitFallback(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	// 转换时记录的是当前目录所在仓库的提交点，因此在临时仓库中转换
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	const source = "package calc\n\nfunc Abs(x int) int {\n\tif x < 0 {\n\t\treturn -x\n\t}\n\treturn x\n}\n"
+	profile := "mode: set\n./calc.go:4.2,4.11 1 1\n./calc.go:4.11,6.3 1 0\n./calc.go:7.2,7.10 1 1\n"
+	if err = ioutil.WriteFile("calc.go", []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile("c.out", []byte(profile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "calc.go"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "calc"},
+	} {
+		if _, err = utils.RunGit("", args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ps, _, err := convert.Do("c.out", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified := strings.Replace(source, "return -x", "return 0 - x", 1)
+	if err = ioutil.WriteFile("calc.go", []byte(modified), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, gitFallback := range []bool{true, false} {
+		var buf bytes.Buffer
+		param := &GenerateHTMLParam{Packages: ps, GitFallback: gitFallback, KeepGoing: true}
+		if err = WriteHTML(&buf, param, false); err != nil {
+			t.Fatal(err)
+		}
+		html := buf.String()
+		stale := strings.Contains(html, "source file has changed")
+		if stale == gitFallback {
+			t.Errorf("gitFallback=%v: report marks the file stale = %v", gitFallback, stale)
+		}
+		if gitFallback && (!strings.Contains(html, "return -x") || strings.Contains(html, "return 0 - x")) {
+			t.Errorf("gitFallback=%v: report should show the committed content", gitFallback)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// WriteText 将覆盖率数据以纯文本格式输出到 w，函数按 sortBy 排序。
+// 记录了覆盖率模式时先输出模式，随后每个包输出一行汇总，每个函数输出一行明细，存在未执行分支的判定点会单独列出，
+// 最后列出风险最高的未完全覆盖函数。
+func WriteText(w io.Writer, ps utils.Packages, sortBy types.SortMethod) error {
+	rps, err := buildReportPackages(ps, nil, sortBy)
+	if err != nil {
+		return err
+	}
+	if mode := ps.Mode(); mode != "" {
+		fmt.Fprintf(w, "Cover mode: %s\n\n", mode)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tFILE\tSTATEMENTS\tLINES\tBLOCKS\tBRANCHES\tCRAP")
+
+	var total [4]metadata.Coverage
+	for _, rp := range rps {
+		pkgCoverage := packageCoverages(rp)
+		for i := range total {
+			total[i].Add(pkgCoverage[i])
+		}
+		fmt.Fprintf(tw, "%s\t\t%s\t\n", rp.Pkg.Name, formatCoverages(pkgCoverage))
+		for _, fn := range rp.Functions {
+			fmt.Fprintf(tw, "  %s\t%s:%d\t%s\t%.1f\n", fn.Name, fn.ShortFileName(), fn.StartLine, formatCoverages(functionCoverages(fn)), fn.CRAP())
+			for _, b := range fn.Branches {
+				if b.Reached() == len(b.Outcomes) {
+					continue
+				}
+				fmt.Fprintf(tw, "    %s:%d\t%s %d/%d branches, missed: %s\n",
+					fn.ShortFileName(), b.Line, b.Kind, b.Reached(), len(b.Outcomes), missedOutcomes(b))
+			}
+		}
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t%s\t\n", formatCoverages(total))
+	if err = tw.Flush(); err != nil {
+		return err
+	}
+
+	riskiest := types.Riskiest(rps, riskiestCount)
+	if len(riskiest) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "\nRISKIEST UNCOVERED FUNCTIONS")
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPACKAGE\tFILE\tCOVERAGE\tCYCLOMATIC\tCOGNITIVE\tCRAP")
+	for _, f := range riskiest {
+		fmt.Fprintf(tw, "%s\t%s\t%s:%d\t%.2f%%\t%d\t%d\t%.1f\n",
+			f.Name, f.Package, filepath.Base(f.File), f.StartLine, f.CoveragePercent(), f.Cyclomatic, f.Cognitive, f.CRAP())
+	}
+	return tw.Flush()
+}
+
+// packageCoverages 按 语句、行、块、分支 的顺序返回包在各统计口径下的覆盖情况
+func packageCoverages(rp types.ReportPackage) [4]metadata.Coverage {
+	return [4]metadata.Coverage{
+		{Reached: rp.ReachedStatements, Total: rp.TotalStatements},
+		rp.LineCoverage,
+		rp.BlockCoverage,
+		rp.BranchCoverage,
+	}
+}
+
+// functionCoverages 按 语句、行、块、分支 的顺序返回函数在各统计口径下的覆盖情况
+func functionCoverages(f types.ReportFunction) [4]metadata.Coverage {
+	return [4]metadata.Coverage{
+		{Reached: f.StatementsReached, Total: len(f.Statements)},
+		f.LineCoverage,
+		f.BlockCoverage,
+		f.BranchCoverage,
+	}
+}
+
+func formatCoverages(cs [4]metadata.Coverage) string {
+	columns := make([]string, len(cs))
+	for i, c := range cs {
+		columns[i] = formatCoverage(c)
+	}
+	return strings.Join(columns, "\t")
+}
+
+func formatCoverage(c metadata.Coverage) string {
+	return fmt.Sprintf("%.2f%% (%d/%d)", c.Percent(), c.Reached, c.Total)
+}
+
+// missedOutcomes 列出判定点中未执行的分支
+func missedOutcomes(b *metadata.Branch) string {
+	missed := make([]string, 0, len(b.Outcomes))
+	for _, o := range b.Outcomes {
+		if o.Count == 0 {
+			missed = append(missed, fmt.Sprintf("%s@%d", o.Label, o.Line))
+		}
+	}
+	return strings.Join(missed, ", ")
+}
@@ -0,0 +1,22 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func TestWriteTextMode(t *testing.T) {
+	ps := utils.Packages{{Name: "calc", Mode: metadata.ModeCount}}
+	var buf bytes.Buffer
+	if err := WriteText(&buf, ps, types.SortByCoverage); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "Cover mode: count\n") {
+		t.Errorf("WriteText() header does not show the cover mode:\n%s", buf.String())
+	}
+}
@@ -137,6 +137,25 @@ func (t defaultTheme) Data() *types.TemplateData {
     }
     a:hover { text-decoration: underline; }
     p { margin-left: 10px; }
+    table.listing td.branches {
+        white-space: nowrap;
+        color: #375eab;
+    }
+    table.listing td.partial {
+        color: #a94442;
+        font-weight: bold;
+    }
+    table.listing td.tests {
+        white-space: nowrap;
+        font-size: 10px;
+    }
+    details.tests, table.listing td.tests details {
+        cursor: pointer;
+    }
+    p.stale {
+        color: #a94442;
+        font-style: italic;
+    }
 </style>`
 	return &types.TemplateData{
 		CSS:        css,
@@ -167,6 +186,7 @@ func (t defaultTheme) Template() *template.Template {
 		<p>no test files in package.</p>"
         {{else}}
         <div id="about">Generated on {{.When}} with <a href="{{.ProjectURL}}">go-cover</a></div>
+        {{if .Mode}}<div id="about">Cover mode: {{.Mode}}</div>{{end}}
         <div id="about">About {{.BranchesInfo.CurrentBranchName}}</div>
         {{/* Report overview/summary available? */}}
         {{if .Overview}}
@@ -177,15 +197,35 @@ func (t defaultTheme) Template() *template.Template {
                 <td><code><a href="#pkg_{{$rp.Pkg.Name}}">{{$rp.Pkg.Name}}</a></code></td>
                 <td class="percent"><code>{{printf "%.2f%%" $rp.PercentageReached}}</code></td>
                 <td class="linecount"><code>{{printf "%d" $rp.ReachedStatements}}/{{printf "%d" $rp.TotalStatements}}</code></td>
+                <td class="percent"><code>lines {{printf "%.2f%%" $rp.LineCoverage.Percent}}</code></td>
+                <td class="linecount"><code>{{$rp.LineCoverage.Reached}}/{{$rp.LineCoverage.Total}}</code></td>
+                <td class="percent"><code>blocks {{printf "%.2f%%" $rp.BlockCoverage.Percent}}</code></td>
+                <td class="linecount"><code>{{$rp.BlockCoverage.Reached}}/{{$rp.BlockCoverage.Total}}</code></td>
+                <td class="percent"><code>branches {{printf "%.2f%%" $rp.BranchCoverage.Percent}}</code></td>
+                <td class="linecount"><code>{{$rp.BranchCoverage.Reached}}/{{$rp.BranchCoverage.Total}}</code></td>
             </tr>
             {{end}}
             </table>
         </div>
         {{end}}
+        {{if .Riskiest}}
+        <div class="funcname">Riskiest Uncovered Functions</div>
+        <table class="overview">
+        {{range $k,$f := .Riskiest}}
+            <tr>
+                <td><code><a href="#fn_{{$f.Name}}">{{$f.Name}}(...)</a></code></td>
+                <td><code>{{$f.Package}}/{{$f.ShortFileName}}</code></td>
+                <td class="percent"><code>{{printf "%.2f%%" $f.CoveragePercent}}</code></td>
+                <td class="linecount"><code>complexity {{$f.Cyclomatic}}/{{$f.Cognitive}}</code></td>
+                <td class="linecount"><code>CRAP {{printf "%.1f" $f.CRAP}}</code></td>
+            </tr>
+        {{end}}
+        </table>
+        {{end}} {{/* if riskiest end */}}
         {{range $k,$rp := .Packages}}
         <div id="pkg_{{$rp.Pkg.Name}}" class="funcname">
             Package Overview: {{$rp.Pkg.Name}}
-            <span class="packageTotal">{{printf "%.2f%%" $rp.PercentageReached}}</span>
+            <span class="packageTotal">{{printf "%.2f%%" $rp.PercentageReached}} (lines {{printf "%.2f%%" $rp.LineCoverage.Percent}}, blocks {{printf "%.2f%%" $rp.BlockCoverage.Percent}}, branches {{printf "%.2f%%" $rp.BranchCoverage.Percent}})</span>
         </div>
         <p>Here are the stats. Please select a function name to view its implementation and see what's left for testing.</p>
 
@@ -204,6 +244,18 @@ func (t defaultTheme) Template() *template.Template {
                 <td class="linecount">
                     <code>{{$f.StatementsReached}}/{{len $f.Statements}}</code>
                 </td>
+                <td class="linecount">
+                    <code>lines {{$f.LineCoverage.Reached}}/{{$f.LineCoverage.Total}}</code>
+                </td>
+                <td class="linecount">
+                    <code>blocks {{$f.BlockCoverage.Reached}}/{{$f.BlockCoverage.Total}}</code>
+                </td>
+                <td class="linecount">
+                    <code>branches {{$f.BranchCoverage.Reached}}/{{$f.BranchCoverage.Total}}</code>
+                </td>
+                <td class="linecount">
+                    <code>CRAP {{printf "%.1f" $f.CRAP}}</code>
+                </td>
             </tr>
         {{end}}
         </table>
@@ -214,17 +266,28 @@ func (t defaultTheme) Template() *template.Template {
         <div class="info">
             <a href="#s_fn_{{$f.Name}}">Back</a>
             <p>In <code>{{$f.File}}</code>:</p>
+            {{with $f.Tests}}
+            <details class="tests"><summary>Covered by {{len .}} tests</summary>
+                {{range $t := .}}<code>{{html $t}}</code><br>{{end}}
+            </details>
+            {{end}}
         </div>
+        {{if $f.Stale}}
+        <p class="stale">Source is not shown: {{html $f.StaleReason}}</p>
+        {{else}}
         <table class="listing">
             {{range $p,$info := $f.Lines}}
             <tr{{if $info.Missed}} class="miss"{{end}}>
                 <td>{{$info.LineNumber}}</td>
+                <td class="branches{{if $info.PartialBranches}} partial{{end}}">{{if $info.Branches}}{{$info.Branches}} branches{{end}}</td>
+                <td class="tests">{{with $info.Tests}}<details><summary>{{len .}} tests</summary>{{range $t := .}}<code>{{html $t}}</code><br>{{end}}</details>{{end}}</td>
                 <td>
                     <code><pre>{{$info.Code}}</pre></code>
                 </td>
             </tr>
             {{end}}
         </table>
+        {{end}} {{/* if stale end */}}
         {{end}} {{/* range function lines */}}
 
         <!--    Can be parsed by external script
@@ -243,6 +306,17 @@ func (t defaultTheme) Template() *template.Template {
         {{end}} {{/* if overview end */}}
         </div>
         {{end}} {{/* range if end */}}
+        {{if .Skipped}}
+        <div class="funcname">Skipped Files</div>
+        <table class="overview">
+        {{range $k,$sf := .Skipped}}
+            <tr>
+                <td><code>{{html $sf.File}}</code></td>
+                <td>{{html $sf.Reason}}</td>
+            </tr>
+        {{end}}
+        </table>
+        {{end}} {{/* if skipped end */}}
 	</body>
 </html>
 {{end}}`
@@ -269,6 +343,7 @@ func (t defaultDiffTheme) Template() *template.Template {
 		<p>no test files in package.</p>"
         {{else}}
         <div id="about">Generated on {{.When}} with <a href="{{.ProjectURL}}">go-cover</a></div>
+        {{if .Mode}}<div id="about">Cover mode: {{.Mode}}</div>{{end}}
 		<div id="about">About {{.BranchesInfo.TargetBranchName}}...{{.BranchesInfo.CurrentBranchName}} From {{.BranchesInfo.StartHashID}} To {{.BranchesInfo.EndHashID}}</div>
         {{/* Report overview/summary available? */}}
         {{if .Overview}}
@@ -279,15 +354,35 @@ func (t defaultDiffTheme) Template() *template.Template {
                 <td><code><a href="#pkg_{{$rp.Pkg.Name}}">{{$rp.Pkg.Name}}</a></code></td>
                 <td class="percent"><code>{{printf "%.2f%%" $rp.PercentageReached}}</code></td>
                 <td class="linecount"><code>{{printf "%d" $rp.ReachedStatements}}/{{printf "%d" $rp.TotalStatements}}</code></td>
+                <td class="percent"><code>lines {{printf "%.2f%%" $rp.LineCoverage.Percent}}</code></td>
+                <td class="linecount"><code>{{$rp.LineCoverage.Reached}}/{{$rp.LineCoverage.Total}}</code></td>
+                <td class="percent"><code>blocks {{printf "%.2f%%" $rp.BlockCoverage.Percent}}</code></td>
+                <td class="linecount"><code>{{$rp.BlockCoverage.Reached}}/{{$rp.BlockCoverage.Total}}</code></td>
+                <td class="percent"><code>branches {{printf "%.2f%%" $rp.BranchCoverage.Percent}}</code></td>
+                <td class="linecount"><code>{{$rp.BranchCoverage.Reached}}/{{$rp.BranchCoverage.Total}}</code></td>
             </tr>
             {{end}}
             </table>
         </div>
         {{end}}
+        {{if .Riskiest}}
+        <div class="funcname">Riskiest Uncovered Functions</div>
+        <table class="overview">
+        {{range $k,$f := .Riskiest}}
+            <tr>
+                <td><code><a href="#fn_{{$f.Name}}">{{$f.Name}}(...)</a></code></td>
+                <td><code>{{$f.Package}}/{{$f.ShortFileName}}</code></td>
+                <td class="percent"><code>{{printf "%.2f%%" $f.CoveragePercent}}</code></td>
+                <td class="linecount"><code>complexity {{$f.Cyclomatic}}/{{$f.Cognitive}}</code></td>
+                <td class="linecount"><code>CRAP {{printf "%.1f" $f.CRAP}}</code></td>
+            </tr>
+        {{end}}
+        </table>
+        {{end}} {{/* if riskiest end */}}
         {{range $k,$rp := .Packages}}
         <div id="pkg_{{$rp.Pkg.Name}}" class="funcname">
             Package Overview: {{$rp.Pkg.Name}}
-            <span class="packageTotal">{{printf "%.2f%%" $rp.PercentageReached}}</span>
+            <span class="packageTotal">{{printf "%.2f%%" $rp.PercentageReached}} (lines {{printf "%.2f%%" $rp.LineCoverage.Percent}}, blocks {{printf "%.2f%%" $rp.BlockCoverage.Percent}}, branches {{printf "%.2f%%" $rp.BranchCoverage.Percent}})</span>
         </div>
         <p>Here are the stats. Please select a function name to view its implementation and see what's left for testing.</p>
 
@@ -306,6 +401,18 @@ func (t defaultDiffTheme) Template() *template.Template {
                 <td class="linecount">
                     <code>{{$f.StatementsReached}}/{{len $f.Statements}}</code>
                 </td>
+                <td class="linecount">
+                    <code>lines {{$f.LineCoverage.Reached}}/{{$f.LineCoverage.Total}}</code>
+                </td>
+                <td class="linecount">
+                    <code>blocks {{$f.BlockCoverage.Reached}}/{{$f.BlockCoverage.Total}}</code>
+                </td>
+                <td class="linecount">
+                    <code>branches {{$f.BranchCoverage.Reached}}/{{$f.BranchCoverage.Total}}</code>
+                </td>
+                <td class="linecount">
+                    <code>CRAP {{printf "%.1f" $f.CRAP}}</code>
+                </td>
             </tr>
         {{end}}
         </table>
@@ -316,7 +423,15 @@ func (t defaultDiffTheme) Template() *template.Template {
         <div class="info">
             <a href="#s_fn_{{$f.Name}}">Back</a>
             <p>In <code>{{$f.File}}</code>:</p>
+            {{with $f.Tests}}
+            <details class="tests"><summary>Covered by {{len .}} tests</summary>
+                {{range $t := .}}<code>{{html $t}}</code><br>{{end}}
+            </details>
+            {{end}}
         </div>
+        {{if $f.Stale}}
+        <p class="stale">Source is not shown: {{html $f.StaleReason}}</p>
+        {{else}}
         <table class="listing">
             {{range $p,$info := $f.Lines}}
             <tr
@@ -327,12 +442,15 @@ func (t defaultDiffTheme) Template() *template.Template {
             {{end}}
             >
                 <td>{{$info.LineNumber}}</td>
+                <td class="branches{{if $info.PartialBranches}} partial{{end}}">{{if $info.Branches}}{{$info.Branches}} branches{{end}}</td>
+                <td class="tests">{{with $info.Tests}}<details><summary>{{len .}} tests</summary>{{range $t := .}}<code>{{html $t}}</code><br>{{end}}</details>{{end}}</td>
                 <td>
                     <code><pre>{{$info.Code}}</pre></code>
                 </td>
             </tr>
             {{end}}
         </table>
+        {{end}} {{/* if stale end */}}
         {{end}} {{/* range function lines */}}
 
         <!--    Can be parsed by external script
@@ -351,6 +469,17 @@ func (t defaultDiffTheme) Template() *template.Template {
         {{end}} {{/* if overview end */}}
         </div>
         {{end}} {{/* range if end */}}
+        {{if .Skipped}}
+        <div class="funcname">Skipped Files</div>
+        <table class="overview">
+        {{range $k,$sf := .Skipped}}
+            <tr>
+                <td><code>{{html $sf.File}}</code></td>
+                <td>{{html $sf.Reason}}</td>
+            </tr>
+        {{end}}
+        </table>
+        {{end}} {{/* if skipped end */}}
 	</body>
 </html>
 {{end}}`
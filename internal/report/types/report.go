@@ -1,11 +1,10 @@
 package types
 
 import (
-	"go/token"
+	"fmt"
 	"html"
-	"io/ioutil"
-	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/lamber92/go-cover/internal/metadata"
@@ -28,6 +27,12 @@ type ReportPackage struct {
 	Functions         ReportFunctionList
 	TotalStatements   int
 	ReachedStatements int
+	// LineCoverage 是包的行覆盖情况
+	LineCoverage metadata.Coverage
+	// BlockCoverage 是包的块覆盖情况
+	BlockCoverage metadata.Coverage
+	// BranchCoverage 是包的分支覆盖情况
+	BranchCoverage metadata.Coverage
 }
 
 // PercentageReached 计算包测试达到的语句的百分比。
@@ -43,6 +48,48 @@ func (rp *ReportPackage) PercentageReached() float64 {
 type ReportFunction struct {
 	*metadata.Function
 	StatementsReached int
+	// LineCoverage 是函数的行覆盖情况
+	LineCoverage metadata.Coverage
+	// BlockCoverage 是函数的块覆盖情况
+	BlockCoverage metadata.Coverage
+	// BranchCoverage 是函数的分支覆盖情况
+	BranchCoverage metadata.Coverage
+	// Source 是函数所在源文件的内容。
+	Source *SourceFile
+}
+
+// SourceFile 是渲染报告时使用的源文件内容。
+type SourceFile struct {
+	Data []byte
+	// Stale 表示源文件缺失或在生成覆盖率数据后已发生变化且无法还原，此时不展示代码行。
+	Stale bool
+	// Reason 是源文件不可用的原因。
+	Reason string
+
+	lineOffsets []int // 每一行的起始偏移量
+}
+
+// NewSourceFile 使用源文件内容创建 SourceFile。
+func NewSourceFile(data []byte) *SourceFile {
+	lineOffsets := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+	return &SourceFile{Data: data, lineOffsets: lineOffsets}
+}
+
+// NewStaleSourceFile 创建一个不可用的 SourceFile。
+func NewStaleSourceFile(reason string) *SourceFile {
+	return &SourceFile{Stale: true, Reason: reason}
+}
+
+// Line 返回偏移量所在的行号(从 1 开始)。
+func (s *SourceFile) Line(offset int) int {
+	return sort.Search(len(s.lineOffsets), func(i int) bool {
+		return s.lineOffsets[i] > offset
+	})
 }
 
 // FunctionLine 保存代码行、它在源文件中的行号以及测试是否到达它。
@@ -51,6 +98,12 @@ type FunctionLine struct {
 	LineNumber int
 	Missed     bool
 	NewCode    bool
+	// Branches 是该行判定点的分支执行情况，如 "1/2"。没有判定点时为空
+	Branches string
+	// PartialBranches 表示该行判定点存在未执行的分支
+	PartialBranches bool
+	// Tests 是执行过该行的测试。只在按测试归因时记录
+	Tests []string
 }
 
 // CoveragePercent 是函数的代码覆盖率百分比。如果函数没有语句，则返回 100。
@@ -70,57 +123,54 @@ func (f ReportFunction) ShortFileName() string {
 	return filepath.Base(f.File)
 }
 
-// Lines 返回有关所有函数代码行的信息。
+// Stale 判断函数的源文件是否不可用。为了方便在主题的HTML模板中使用而提供。
+func (f ReportFunction) Stale() bool {
+	return f.Source == nil || f.Source.Stale ||
+		f.Start < 0 || f.Start > f.End || f.End > len(f.Source.Data)
+}
+
+// StaleReason 返回函数的源文件不可用的原因。
+func (f ReportFunction) StaleReason() string {
+	if f.Source == nil {
+		return "source file is not loaded"
+	}
+	if f.Source.Stale {
+		return f.Source.Reason
+	}
+	return "source ranges are out of the file content"
+}
+
+// Lines 返回有关所有函数代码行的信息。源文件不可用时返回 nil。
 func (f ReportFunction) Lines() []FunctionLine {
-	type annotator struct {
-		fset  *token.FileSet
-		files map[string]*token.File
-	}
-	a := &annotator{}
-	a.fset = token.NewFileSet()
-	a.files = make(map[string]*token.File)
-
-	// 加载行信息文件。可能矫枉过正，也许只是在这里计算偏移量的线。
-	setContent := false
-	file := a.files[f.File]
-	if file == nil {
-		info, err := os.Stat(f.File)
-		if err != nil {
-			panic(err)
-		}
-		file = a.fset.AddFile(f.File, a.fset.Base(), int(info.Size()))
-		setContent = true
+	if f.Stale() {
+		return nil
 	}
+	src := f.Source
 
-	data, err := ioutil.ReadFile(f.File)
-	if err != nil {
-		panic(err)
+	// 记录每个语句起始行是否被执行
+	reached := make(map[int]bool, len(f.Statements))
+	for _, stmt := range f.Statements {
+		line := src.Line(stmt.Start)
+		reached[line] = reached[line] || stmt.Reached > 0
 	}
 
-	if setContent {
-		// 这会处理内容并记录行号信息。
-		file.SetLinesForContent(data)
+	// 记录每个判定点所在行的分支执行情况
+	branches := make(map[int]metadata.Coverage, len(f.Branches))
+	for _, b := range f.Branches {
+		c := branches[b.Line]
+		c.Add(metadata.Coverage{Reached: b.Reached(), Total: len(b.Outcomes)})
+		branches[b.Line] = c
 	}
 
-	statements := f.Statements[:]
-	lineno := file.Line(file.Pos(f.Start))
-	lines := strings.Split(string(data)[f.Start:f.End], "\n")
+	lineTests := f.LineTests()
+
+	lineno := src.Line(f.Start)
+	lines := strings.Split(string(src.Data[f.Start:f.End]), "\n")
 	fls := make([]FunctionLine, len(lines))
 
 	for i, line := range lines {
 		lineno := lineno + i
-		statementFound := false
-		hit := false
-		for j := 0; j < len(statements); j++ {
-			start := file.Line(file.Pos(statements[j].Start))
-			if start == lineno {
-				statementFound = true
-				if !hit && statements[j].Reached > 0 {
-					hit = true
-				}
-				statements = append(statements[:j], statements[j+1:]...)
-			}
-		}
+		hit, statementFound := reached[lineno]
 		hitmiss := hitPrefix
 		newCode := false
 		if statementFound && !hit {
@@ -135,6 +185,11 @@ func (f ReportFunction) Lines() []FunctionLine {
 			NewCode:    newCode,
 			LineNumber: lineno,
 			Code:       html.EscapeString(strings.Replace(line, "\t", "        ", -1)),
+			Tests:      lineTests[lineno],
+		}
+		if c, ok := branches[lineno]; ok {
+			fls[i].Branches = fmt.Sprintf("%d/%d", c.Reached, c.Total)
+			fls[i].PartialBranches = c.Reached < c.Total
 		}
 	}
 	return fls
@@ -147,8 +202,7 @@ func (l ReportFunctionList) Len() int {
 	return len(l)
 }
 
-// Less
-// TODO: make sort method configurable?
+// Less 按语句覆盖率比较，覆盖率相同时语句少的在前。
 func (l ReportFunctionList) Less(i, j int) bool {
 	var left, right float64
 	if len(l[i].Statements) > 0 {
@@ -167,6 +221,72 @@ func (l ReportFunctionList) Swap(i, j int) {
 	l[i], l[j] = l[j], l[i]
 }
 
+// SortMethod 是报告中函数列表的排序方式
+type SortMethod string
+
+const (
+	SortByCoverage   SortMethod = "coverage"   // 语句覆盖率从高到低
+	SortByUncovered  SortMethod = "uncovered"  // 语句覆盖率从低到高
+	SortByCRAP       SortMethod = "crap"       // CRAP 分数从高到低
+	SortByComplexity SortMethod = "complexity" // 圈复杂度从高到低
+	SortByName       SortMethod = "name"       // 函数名称字典序
+)
+
+// SortMethods 是所有支持的排序方式
+var SortMethods = []SortMethod{SortByCoverage, SortByUncovered, SortByCRAP, SortByComplexity, SortByName}
+
+// ParseSortMethod 解析排序方式，空字符串视为 SortByCoverage
+func ParseSortMethod(s string) (SortMethod, error) {
+	if s == "" {
+		return SortByCoverage, nil
+	}
+	for _, m := range SortMethods {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported sort method. [%s]", s)
+}
+
+// SortBy 按指定方式对函数列表排序，无法区分先后的函数保持原有顺序
+func (l ReportFunctionList) SortBy(m SortMethod) {
+	switch m {
+	case SortByUncovered:
+		sort.Stable(l)
+	case SortByCRAP:
+		sort.SliceStable(l, func(i, j int) bool { return l[i].CRAP() > l[j].CRAP() })
+	case SortByComplexity:
+		sort.SliceStable(l, func(i, j int) bool { return l[i].Cyclomatic > l[j].Cyclomatic })
+	case SortByName:
+		sort.SliceStable(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+	default:
+		sort.Stable(sort.Reverse(l))
+	}
+}
+
+// RiskyFunction 是风险排名中的函数及其所在的包
+type RiskyFunction struct {
+	Package string
+	ReportFunction
+}
+
+// Riskiest 返回未被完全覆盖的函数中 CRAP 分数最高的 n 个，按分数从高到低排列
+func Riskiest(ps ReportPackageList, n int) []RiskyFunction {
+	var rv []RiskyFunction
+	for _, p := range ps {
+		for _, f := range p.Functions {
+			if f.CoveragePercent() < 100 {
+				rv = append(rv, RiskyFunction{Package: p.Pkg.Name, ReportFunction: f})
+			}
+		}
+	}
+	sort.SliceStable(rv, func(i, j int) bool { return rv[i].CRAP() > rv[j].CRAP() })
+	if len(rv) > n {
+		rv = rv[:n]
+	}
+	return rv
+}
+
 // BranchesInfo 提交信息
 type BranchesInfo struct {
 	TargetBranchName  string
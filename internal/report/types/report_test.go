@@ -0,0 +1,95 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+)
+
+// newFunction 创建有 total 个语句、其中 reached 个被执行过的函数
+func newFunction(name string, cyclomatic, total, reached int) ReportFunction {
+	f := &metadata.Function{Name: name, Cyclomatic: cyclomatic}
+	for i := 0; i < total; i++ {
+		s := &metadata.Statement{}
+		if i < reached {
+			s.Reached = 1
+		}
+		f.Statements = append(f.Statements, s)
+	}
+	return ReportFunction{Function: f, StatementsReached: reached}
+}
+
+// testFunctions 返回 CRAP 分数依次为 8.125、1、2.5、12 的函数
+func testFunctions() ReportFunctionList {
+	return ReportFunctionList{
+		newFunction("Gamma", 5, 4, 2),
+		newFunction("Alpha", 1, 4, 4),
+		newFunction("Delta", 2, 2, 1),
+		newFunction("Beta", 3, 4, 0),
+	}
+}
+
+func names(l ReportFunctionList) []string {
+	rv := make([]string, len(l))
+	for i, f := range l {
+		rv[i] = f.Name
+	}
+	return rv
+}
+
+func TestSortBy(t *testing.T) {
+	tests := []struct {
+		method SortMethod
+		want   []string
+	}{
+		// 覆盖率相同时语句多的在前
+		{SortByCoverage, []string{"Alpha", "Gamma", "Delta", "Beta"}},
+		{SortByUncovered, []string{"Beta", "Delta", "Gamma", "Alpha"}},
+		{SortByCRAP, []string{"Beta", "Gamma", "Delta", "Alpha"}},
+		{SortByComplexity, []string{"Gamma", "Beta", "Delta", "Alpha"}},
+		{SortByName, []string{"Alpha", "Beta", "Delta", "Gamma"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			l := testFunctions()
+			l.SortBy(tt.method)
+			if got := names(l); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortBy(%s) = %v, want %v", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRiskiest(t *testing.T) {
+	ps := ReportPackageList{
+		{Pkg: &metadata.Package{Name: "a"}, Functions: testFunctions()[:2]},
+		{Pkg: &metadata.Package{Name: "b"}, Functions: testFunctions()[2:]},
+	}
+	// 完全覆盖的 Alpha 不参与排名
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{2, []string{"Beta", "Gamma"}},
+		{10, []string{"Beta", "Gamma", "Delta"}},
+		{0, []string{}},
+	}
+	for _, tt := range tests {
+		got := Riskiest(ps, tt.n)
+		if !reflect.DeepEqual(riskyNames(got), tt.want) {
+			t.Errorf("Riskiest(%d) = %v, want %v", tt.n, riskyNames(got), tt.want)
+		}
+	}
+	if got := Riskiest(ps, 1); len(got) != 1 || got[0].Package != "b" {
+		t.Errorf("Riskiest(1) = %+v, want Beta in package b", got)
+	}
+}
+
+func riskyNames(l []RiskyFunction) []string {
+	rv := make([]string, len(l))
+	for i, f := range l {
+		rv[i] = f.Name
+	}
+	return rv
+}
@@ -1,6 +1,10 @@
 package types
 
-import "text/template"
+import (
+	"text/template"
+
+	"github.com/lamber92/go-cover/internal/errs"
+)
 
 // Beautifier 定义了一个用于呈现 HTML 覆盖率统计信息的主题。
 type Beautifier interface {
@@ -29,4 +33,10 @@ type TemplateData struct {
 	ProjectURL string
 	// BranchesInfo
 	BranchesInfo *BranchesInfo //
+	// Mode is the cover mode of the profile (set, count or atomic). Empty if not recorded.
+	Mode string
+	// Skipped is the list of files that were skipped (missing, unparsable or stale).
+	Skipped []*errs.SkippedFile
+	// Riskiest is the list of uncovered functions with the highest CRAP scores.
+	Riskiest []RiskyFunction
 }
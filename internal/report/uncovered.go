@@ -0,0 +1,61 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// lineRange 是连续的代码行区间，包含首尾两行
+type lineRange struct {
+	start int
+	end   int
+}
+
+// lineRanges 将有序的行号合并为连续区间
+func lineRanges(lines []int) []lineRange {
+	var ranges []lineRange
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		ranges = append(ranges, lineRange{start: lines[i], end: lines[j]})
+		i = j + 1
+	}
+	return ranges
+}
+
+func formatRange(r lineRange) string {
+	if r.start == r.end {
+		return strconv.Itoa(r.start)
+	}
+	return fmt.Sprintf("%d-%d", r.start, r.end)
+}
+
+// WriteUncovered 以纯文本格式输出增量数据的行覆盖率，以及每段未被执行的新代码行
+func WriteUncovered(w io.Writer, ps utils.Packages) error {
+	var total metadata.Coverage
+	for _, p := range ps {
+		total.Add(p.Coverage(metadata.MetricLine))
+	}
+	fmt.Fprintf(w, "Diff coverage: %s of new lines\n", formatCoverage(total))
+	if total.Reached == total.Total {
+		fmt.Fprintln(w, "All new lines are covered.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, p := range ps {
+		for _, f := range p.Functions {
+			for _, r := range lineRanges(f.MissedLines()) {
+				fmt.Fprintf(tw, "  %s:%s\t%s\n", f.File, formatRange(r), f.Name)
+			}
+		}
+	}
+	return tw.Flush()
+}
@@ -4,11 +4,11 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"sort"
 
 	"github.com/lamber92/go-cover/internal/metadata"
 	"github.com/lamber92/go-cover/internal/report/themes"
 	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
 )
 
 // writeFullReport 写全量报告
@@ -35,22 +35,25 @@ func (b *basicWriter) Do(w io.Writer, r *report) error {
 		// Inline CSS.
 		f, err := os.Open(r.stylesheet)
 		if err != nil {
-			return fmt.Errorf("print report. err: %v", err)
+			return fmt.Errorf("print report. err: %w", err)
 		}
 		style, err := io.ReadAll(f)
 		if err != nil {
-			return fmt.Errorf("read style. err: %v", err)
+			return fmt.Errorf("read style. err: %w", err)
 		}
 		css = string(style)
 	}
-	reportPackages := make(types.ReportPackageList, len(r.packages))
-	for i, pkg := range r.packages {
-		reportPackages[i] = b.buildReportPackage(pkg)
+	reportPackages, err := buildReportPackages(r.packages, r.sources, r.sort)
+	if err != nil {
+		return err
 	}
 
 	data.CSS = css
 	data.Packages = reportPackages
 	data.BranchesInfo = r.commit
+	data.Mode = r.packages.Mode()
+	data.Skipped = append(append(data.Skipped, r.skipped...), r.sources.skipped...)
+	data.Riskiest = types.Riskiest(reportPackages, riskiestCount)
 
 	if len(reportPackages) > 1 {
 		rv := types.ReportPackage{
@@ -59,24 +62,37 @@ func (b *basicWriter) Do(w io.Writer, r *report) error {
 		for _, rp := range reportPackages {
 			rv.ReachedStatements += rp.ReachedStatements
 			rv.TotalStatements += rp.TotalStatements
+			rv.LineCoverage.Add(rp.LineCoverage)
+			rv.BlockCoverage.Add(rp.BlockCoverage)
+			rv.BranchCoverage.Add(rp.BranchCoverage)
 		}
 		data.Overview = &rv
 	}
 	if err := theme.Template().Execute(w, data); err != nil {
-		return fmt.Errorf("execute template. err: %v", err)
+		return fmt.Errorf("execute template. err: %w", err)
 	}
 	return nil
 }
 
-type reverse struct {
-	sort.Interface
-}
+// riskiestCount 是报告中风险排名列出的函数数量
+const riskiestCount = 10
 
-func (r reverse) Less(i, j int) bool {
-	return r.Interface.Less(j, i)
+// buildReportPackages 为每个包构建报告数据，并按 sortBy 对函数排序。
+// sources 为 nil 时不加载源文件，适用于不展示代码行的报告。
+func buildReportPackages(ps utils.Packages, sources *sourceLoader, sortBy types.SortMethod) (types.ReportPackageList, error) {
+	rv := make(types.ReportPackageList, len(ps))
+	for i, pkg := range ps {
+		rp, err := buildReportPackage(pkg, sources)
+		if err != nil {
+			return nil, err
+		}
+		rp.Functions.SortBy(sortBy)
+		rv[i] = rp
+	}
+	return rv, nil
 }
 
-func (b *basicWriter) buildReportPackage(pkg *metadata.Package) types.ReportPackage {
+func buildReportPackage(pkg *metadata.Package, sources *sourceLoader) (types.ReportPackage, error) {
 	rv := types.ReportPackage{
 		Pkg:       pkg,
 		Functions: make(types.ReportFunctionList, len(pkg.Functions)),
@@ -88,10 +104,26 @@ func (b *basicWriter) buildReportPackage(pkg *metadata.Package) types.ReportPack
 				reached++
 			}
 		}
-		rv.Functions[i] = types.ReportFunction{Function: fn, StatementsReached: reached}
+		var source *types.SourceFile
+		if sources != nil {
+			var err error
+			if source, err = sources.load(pkg, fn.File); err != nil {
+				return rv, err
+			}
+		}
+		rv.Functions[i] = types.ReportFunction{
+			Function:          fn,
+			StatementsReached: reached,
+			LineCoverage:      fn.Coverage(metadata.MetricLine),
+			BlockCoverage:     fn.Coverage(metadata.MetricBlock),
+			BranchCoverage:    fn.Coverage(metadata.MetricBranch),
+			Source:            source,
+		}
 		rv.TotalStatements += len(fn.Statements)
 		rv.ReachedStatements += reached
+		rv.LineCoverage.Add(rv.Functions[i].LineCoverage)
+		rv.BlockCoverage.Add(rv.Functions[i].BlockCoverage)
+		rv.BranchCoverage.Add(rv.Functions[i].BranchCoverage)
 	}
-	sort.Sort(reverse{rv.Functions})
-	return rv
+	return rv, nil
 }
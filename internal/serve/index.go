@@ -0,0 +1,108 @@
+package serve
+
+import (
+	"io"
+	"net/url"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/themes"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// indexFile 是首页中的一个文件
+type indexFile struct {
+	Package  string
+	File     string
+	Coverage metadata.Coverage
+}
+
+// Link 返回文件页面的地址
+func (f indexFile) Link() string {
+	return "/file?path=" + url.QueryEscape(f.File)
+}
+
+// ShortName 返回文件名
+func (f indexFile) ShortName() string {
+	return filepath.Base(f.File)
+}
+
+// writeIndex 输出首页：按包列出每个文件的语句覆盖率，并提供按包名或文件名搜索
+func writeIndex(w io.Writer, packages utils.Packages, version int64) error {
+	var total metadata.Coverage
+	var indexFiles []indexFile
+	for _, p := range packages {
+		covByFile := make(map[string]*metadata.Coverage)
+		for _, f := range p.Functions {
+			c, ok := covByFile[f.File]
+			if !ok {
+				c = &metadata.Coverage{}
+				covByFile[f.File] = c
+			}
+			c.Add(f.Coverage(metadata.MetricStatement))
+		}
+		for _, file := range files(p) {
+			indexFiles = append(indexFiles, indexFile{Package: p.Name, File: file, Coverage: *covByFile[file]})
+			total.Add(*covByFile[file])
+		}
+	}
+	return indexTemplate.Execute(w, struct {
+		CSS        string
+		When       string
+		ProjectURL string
+		Total      metadata.Coverage
+		Files      []indexFile
+		Reload     string
+	}{
+		CSS:        themes.Current().Data().CSS,
+		When:       time.Now().Format(time.RFC822Z),
+		ProjectURL: types.ProjectURL,
+		Total:      total,
+		Files:      indexFiles,
+		Reload:     reloadScript(version),
+	})
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Coverage Report</title>
+		{{.CSS}}
+		<style type="text/css">
+		    #search { margin: 10px; padding: 5px; width: 400px; }
+		</style>
+	</head>
+	<body>
+		<div id="doctitle">Coverage Report</div>
+        <div id="about">Generated on {{.When}} with <a href="{{.ProjectURL}}">go-cover</a></div>
+        <div class="funcname">
+            Files
+            <span class="packageTotal">{{printf "%.2f%%" .Total.Percent}} (<a href="/report">full report</a>)</span>
+        </div>
+        <input id="search" type="search" placeholder="Search packages or files">
+        <table class="overview" id="files">
+        {{range $k,$f := .Files}}
+            <tr data-search="{{html $f.Package}}/{{html $f.ShortName}}">
+                <td><code>{{html $f.Package}}</code></td>
+                <td><code><a href="{{html $f.Link}}">{{html $f.ShortName}}</a></code></td>
+                <td class="percent"><code>{{printf "%.2f%%" $f.Coverage.Percent}}</code></td>
+                <td class="linecount"><code>{{$f.Coverage.Reached}}/{{$f.Coverage.Total}}</code></td>
+            </tr>
+        {{end}}
+        </table>
+        <script>
+        document.getElementById("search").addEventListener("input", function(e) {
+            var q = e.target.value.toLowerCase();
+            var rows = document.querySelectorAll("#files tr");
+            for (var i = 0; i < rows.length; i++) {
+                rows[i].style.display = rows[i].getAttribute("data-search").toLowerCase().indexOf(q) >= 0 ? "" : "none";
+            }
+        });
+        </script>
+        {{.Reload}}
+	</body>
+</html>
+`))
@@ -0,0 +1,341 @@
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/errs"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+// DefaultInterval 是检查数据文件变更的默认间隔
+const DefaultInterval = time.Second
+
+// Loader 加载覆盖率数据
+type Loader func() (utils.Packages, []*errs.SkippedFile, error)
+
+type Param struct {
+	// Files 是需要监视的数据文件，任一文件变更时重新加载
+	Files []string
+	// Load 加载覆盖率数据
+	Load Loader
+	// Interval 是检查数据文件变更的间隔，缺省时使用 DefaultInterval
+	Interval time.Duration
+	// Render 是渲染 HTML 报告的参数，其中的 Packages 与 Skipped 会被忽略
+	Render report.GenerateHTMLParam
+}
+
+// Server 是提供覆盖率报告与 JSON 接口的 HTTP 服务，数据文件变更时自动重新加载
+type Server struct {
+	param *Param
+	mux   *http.ServeMux
+
+	mu       sync.RWMutex
+	packages utils.Packages
+	skipped  []*errs.SkippedFile
+	version  int64
+	stats    map[string]fileStat
+}
+
+// fileStat 记录数据文件的修改时间与大小，用于判断文件是否变更
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// New 加载覆盖率数据并创建服务
+func New(param *Param) (*Server, error) {
+	if param.Interval <= 0 {
+		param.Interval = DefaultInterval
+	}
+	s := &Server{param: param, mux: http.NewServeMux()}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/report", s.handleReport)
+	s.mux.HandleFunc("/file", s.handleFile)
+	s.mux.HandleFunc("/api/packages", s.handlePackages)
+	s.mux.HandleFunc("/api/functions", s.handleFunctions)
+	s.mux.HandleFunc("/api/version", s.handleVersion)
+	return s, nil
+}
+
+// ServeHTTP 实现了 http.Handler 接口
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Watch 按间隔检查数据文件，变更时重新加载，直到 stop 被关闭。
+// 重新加载失败时保留上一次的数据。
+func (s *Server) Watch(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.param.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !s.changed() {
+				continue
+			}
+			if err := s.reload(); err != nil {
+				log.Printf("Reload coverage failed. err: %v\n", err)
+				continue
+			}
+			log.Println("Reload coverage success.")
+		}
+	}
+}
+
+// Version 返回数据的版本号，每次重新加载后递增
+func (s *Server) Version() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+func (s *Server) reload() error {
+	stats := statFiles(s.param.Files)
+	packages, skipped, err := s.param.Load()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.packages = packages
+	s.skipped = skipped
+	s.stats = stats
+	s.version++
+	return nil
+}
+
+// changed 判断数据文件自上次加载后是否变更
+func (s *Server) changed() bool {
+	stats := statFiles(s.param.Files)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for file, stat := range stats {
+		if s.stats[file] != stat {
+			return true
+		}
+	}
+	return len(stats) != len(s.stats)
+}
+
+func statFiles(files []string) map[string]fileStat {
+	stats := make(map[string]fileStat, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			stats[file] = fileStat{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stats
+}
+
+// snapshot 返回当前的数据
+func (s *Server) snapshot() (utils.Packages, []*errs.SkippedFile, int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.packages, s.skipped, s.version
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	packages, _, version := s.snapshot()
+	s.writeHTML(w, func(buf *bytes.Buffer) error {
+		return writeIndex(buf, packages, version)
+	})
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	packages, skipped, version := s.snapshot()
+	s.writeHTML(w, func(buf *bytes.Buffer) error {
+		return s.writeReport(buf, packages, skipped, version)
+	})
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	packages, _, version := s.snapshot()
+	filtered := filterFile(packages, path)
+	if len(filtered) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	s.writeHTML(w, func(buf *bytes.Buffer) error {
+		return s.writeReport(buf, filtered, nil, version)
+	})
+}
+
+// writeReport 渲染 HTML 报告并注入自动刷新脚本
+func (s *Server) writeReport(buf *bytes.Buffer, packages utils.Packages, skipped []*errs.SkippedFile, version int64) error {
+	param := s.param.Render
+	param.Packages = packages
+	param.Skipped = skipped
+	if err := report.WriteHTML(buf, &param, false); err != nil {
+		return err
+	}
+	html := buf.String()
+	buf.Reset()
+	i := strings.LastIndex(html, "</body>")
+	if i < 0 {
+		i = len(html)
+	}
+	buf.WriteString(html[:i])
+	buf.WriteString(reloadScript(version))
+	buf.WriteString(html[i:])
+	return nil
+}
+
+func (s *Server) writeHTML(w http.ResponseWriter, render func(buf *bytes.Buffer) error) {
+	buf := &bytes.Buffer{}
+	if err := render(buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = buf.WriteTo(w)
+}
+
+// filterFile 返回只包含指定文件中函数的数据
+func filterFile(packages utils.Packages, path string) utils.Packages {
+	var rv utils.Packages
+	for _, p := range packages {
+		var functions []*metadata.Function
+		for _, f := range p.Functions {
+			if f.File == path {
+				functions = append(functions, f)
+			}
+		}
+		if len(functions) > 0 {
+			pkg := &metadata.Package{Name: p.Name, Mode: p.Mode, Functions: functions}
+			pkg.AddSource(p.Source(path))
+			rv = append(rv, pkg)
+		}
+	}
+	return rv
+}
+
+// packageSummary 是 /api/packages 返回的包信息
+type packageSummary struct {
+	Name     string
+	Coverage map[metadata.Metric]metadata.Coverage
+	Files    []string
+}
+
+// functionSummary 是 /api/functions 返回的函数信息
+type functionSummary struct {
+	Package    string
+	Name       string
+	File       string
+	StartLine  int
+	EndLine    int
+	Cyclomatic int
+	Cognitive  int
+	CRAP       float64
+	Coverage   map[metadata.Metric]metadata.Coverage
+}
+
+func coverages(coverage func(metadata.Metric) metadata.Coverage) map[metadata.Metric]metadata.Coverage {
+	rv := make(map[metadata.Metric]metadata.Coverage, len(metadata.Metrics))
+	for _, m := range metadata.Metrics {
+		rv[m] = coverage(m)
+	}
+	return rv
+}
+
+// files 返回包中函数所在的文件
+func files(p *metadata.Package) []string {
+	set := make(map[string]struct{})
+	for _, f := range p.Functions {
+		set[f.File] = struct{}{}
+	}
+	rv := make([]string, 0, len(set))
+	for f := range set {
+		rv = append(rv, f)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+func (s *Server) handlePackages(w http.ResponseWriter, r *http.Request) {
+	packages, _, _ := s.snapshot()
+	rv := make([]*packageSummary, 0, len(packages))
+	for _, p := range packages {
+		rv = append(rv, &packageSummary{Name: p.Name, Coverage: coverages(p.Coverage), Files: files(p)})
+	}
+	writeJSON(w, rv)
+}
+
+// handleFunctions 返回函数列表，可按 package、file 精确过滤，按 q 模糊匹配函数名或文件
+func (s *Server) handleFunctions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pkgName, file, q := query.Get("package"), query.Get("file"), strings.ToLower(query.Get("q"))
+	packages, _, _ := s.snapshot()
+	rv := make([]*functionSummary, 0)
+	for _, p := range packages {
+		if pkgName != "" && p.Name != pkgName {
+			continue
+		}
+		for _, f := range p.Functions {
+			if file != "" && f.File != file {
+				continue
+			}
+			if q != "" && !strings.Contains(strings.ToLower(f.Name), q) && !strings.Contains(strings.ToLower(f.File), q) {
+				continue
+			}
+			rv = append(rv, &functionSummary{
+				Package:    p.Name,
+				Name:       f.Name,
+				File:       f.File,
+				StartLine:  f.StartLine,
+				EndLine:    f.EndLine,
+				Cyclomatic: f.Cyclomatic,
+				Cognitive:  f.Cognitive,
+				CRAP:       f.CRAP(),
+				Coverage:   coverages(f.Coverage),
+			})
+		}
+	}
+	writeJSON(w, rv)
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, struct{ Version int64 }{s.Version()})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Write response failed. err: %v\n", err)
+	}
+}
+
+// reloadScript 返回轮询数据版本、版本变化时刷新页面的脚本
+func reloadScript(version int64) string {
+	return fmt.Sprintf(`<script>
+(function() {
+    var version = %d;
+    setInterval(function() {
+        fetch("/api/version").then(function(r) { return r.json(); }).then(function(v) {
+            if (v.Version !== version) { location.reload(); }
+        }).catch(function() {});
+    }, 2000);
+})();
+</script>
+`, version)
+}
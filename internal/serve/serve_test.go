@@ -0,0 +1,96 @@
+package serve
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamber92/go-cover/internal/errs"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func newTestServer(t *testing.T) (*Server, string) {
+	file := filepath.Join(t.TempDir(), "coverage.json")
+	if err := ioutil.WriteFile(file, []byte("1"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(&Param{
+		Files: []string{file},
+		Load: func() (utils.Packages, []*errs.SkippedFile, error) {
+			return utils.Packages{{Name: "pkg", Functions: []*metadata.Function{
+				{Name: "Foo", File: "pkg/foo.go", Statements: []*metadata.Statement{{Reached: 1}, {}}},
+				{Name: "Bar", File: "pkg/bar.go", Statements: []*metadata.Statement{{Reached: 1}}},
+			}}}, nil, nil
+		},
+		Interval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, file
+}
+
+func get(t *testing.T, h http.Handler, url string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	return rec
+}
+
+func TestServerAPI(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	var functions []*functionSummary
+	if err := json.Unmarshal(get(t, s, "/api/functions?q=foo").Body.Bytes(), &functions); err != nil {
+		t.Fatal(err)
+	}
+	if len(functions) != 1 || functions[0].Name != "Foo" || functions[0].Coverage[metadata.MetricStatement].Reached != 1 {
+		t.Errorf("/api/functions?q=foo = %+v, want Foo with 1 reached statement", functions)
+	}
+
+	var packages []*packageSummary
+	if err := json.Unmarshal(get(t, s, "/api/packages").Body.Bytes(), &packages); err != nil {
+		t.Fatal(err)
+	}
+	if len(packages) != 1 || len(packages[0].Files) != 2 {
+		t.Errorf("/api/packages = %+v, want 1 package with 2 files", packages)
+	}
+
+	if rec := get(t, s, "/"); !strings.Contains(rec.Body.String(), "/file?path=pkg%2Ffoo.go") {
+		t.Errorf("index page does not link to the file page")
+	}
+	if rec := get(t, s, "/file?path=missing.go"); rec.Code != http.StatusNotFound {
+		t.Errorf("/file?path=missing.go code = %d, want 404", rec.Code)
+	}
+	// 源文件不存在，报告中标记为不可用，但页面仍可渲染
+	s.param.Render.KeepGoing = true
+	s.param.Render.Sort = types.SortByName
+	if rec := get(t, s, "/file?path=pkg/foo.go"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "/api/version") {
+		t.Errorf("/file?path=pkg/foo.go code = %d, want 200 with the reload script", rec.Code)
+	}
+}
+
+func TestServerWatch(t *testing.T) {
+	s, file := newTestServer(t)
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.Watch(stop)
+
+	// 修改文件大小，保证变更可被检测到
+	if err := ioutil.WriteFile(file, []byte("22"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Version() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s.Version() != 2 {
+		t.Errorf("Version() = %d after the file changed, want 2", s.Version())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/lamber92/go-cover/internal/errs"
 	"github.com/lamber92/go-cover/internal/metadata"
 	"github.com/lamber92/go-cover/internal/utils"
 )
@@ -15,19 +16,20 @@ import (
 func Do(sourcePath string, reserveRulesPath string) (out utils.Packages, err error) {
 	file, err := os.OpenFile(sourcePath, os.O_RDONLY, 0666)
 	if err != nil {
-		err = fmt.Errorf("failed to open coverage.json. path: %s, err: %v", sourcePath, err)
+		err = fmt.Errorf("failed to open coverage.json. path: %s, err: %w", sourcePath, err)
 		return
 	}
 	defer file.Close()
 
 	buffer, err := io.ReadAll(file)
 	if err != nil {
-		err = fmt.Errorf("failed to read coverage.json. err: %v", err)
+		err = &errs.ParseError{File: sourcePath, Err: err}
 		return
 	}
 	packages, err := utils.UnmarshalJson(buffer)
 	if err != nil {
-		err = fmt.Errorf("failed to unmarshal coverage.json")
+		err = &errs.ParseError{File: sourcePath, Err: err}
+		return
 	}
 
 	info, err := utils.LoadReservedInfo(reserveRulesPath)
@@ -48,7 +50,7 @@ func TrimPackages(packages utils.Packages, rules metadata.ReservedRules) (out ut
 func trimPackages(source utils.Packages, reserveRules metadata.ReservedRules) (out utils.Packages, err error) {
 	prefix, err := os.Getwd()
 	if err != nil {
-		err = fmt.Errorf("failed to get pwd. err: %v", err)
+		err = fmt.Errorf("failed to get pwd. err: %w", err)
 		return
 	}
 	prefix = prefix + string(filepath.Separator)
@@ -58,6 +60,7 @@ func trimPackages(source utils.Packages, reserveRules metadata.ReservedRules) (o
 	for _, pkg := range source {
 		newPkg := &metadata.Package{
 			Name:      pkg.Name,
+			Mode:      pkg.Mode,
 			Functions: make([]*metadata.Function, 0),
 		}
 
@@ -67,6 +70,10 @@ func trimPackages(source utils.Packages, reserveRules metadata.ReservedRules) (o
 				File:       function.File,
 				Start:      function.Start,
 				End:        function.End,
+				StartLine:  function.StartLine,
+				EndLine:    function.EndLine,
+				Cyclomatic: function.Cyclomatic,
+				Cognitive:  function.Cognitive,
 				Statements: make([]*metadata.Statement, 0),
 				NewLineSet: make(map[int]struct{}),
 			}
@@ -107,10 +114,30 @@ func trimPackages(source utils.Packages, reserveRules metadata.ReservedRules) (o
 						}
 					}
 				}
+
+				// 保留判定点或分支位于新代码行的判定点
+				for _, branch := range function.Branches {
+					if reserveBranch(branch, rule.LinesSet) {
+						newFunction.Branches = append(newFunction.Branches, copyBranch(branch))
+					}
+				}
+
+				// 保留包含新代码行的原始覆盖块
+				for _, block := range function.Blocks {
+					for i := block.StartLine; i <= block.LastLine(); i++ {
+						if _, exist := rule.LinesSet[i]; exist {
+							newBlock := *block
+							newBlock.Tests = append([]string(nil), block.Tests...)
+							newFunction.Blocks = append(newFunction.Blocks, &newBlock)
+							break
+						}
+					}
+				}
 			}
 
 			if len(newFunction.Statements) > 0 {
 				newPkg.Functions = append(newPkg.Functions, newFunction)
+				newPkg.AddSource(pkg.Source(function.File))
 			}
 		}
 
@@ -122,6 +149,30 @@ func trimPackages(source utils.Packages, reserveRules metadata.ReservedRules) (o
 	return
 }
 
+// copyBranch 深拷贝判定点，裁剪结果与原始数据不共享分支
+func copyBranch(branch *metadata.Branch) *metadata.Branch {
+	newBranch := *branch
+	newBranch.Outcomes = make([]*metadata.Outcome, 0, len(branch.Outcomes))
+	for _, o := range branch.Outcomes {
+		newOutcome := *o
+		newBranch.Outcomes = append(newBranch.Outcomes, &newOutcome)
+	}
+	return &newBranch
+}
+
+// reserveBranch 判断判定点或其任一分支是否位于需要保留的行
+func reserveBranch(branch *metadata.Branch, lines map[int]struct{}) bool {
+	if _, exist := lines[branch.Line]; exist {
+		return true
+	}
+	for _, o := range branch.Outcomes {
+		if _, exist := lines[o.Line]; exist {
+			return true
+		}
+	}
+	return false
+}
+
 // judgeTwoAreaOverlap 判断两个区间是否重叠(左闭右闭区间)
 func judgeTwoAreaOverlap(start1, end1, start2, end2 int) bool {
 	return start2 <= end1 && end2 >= start1
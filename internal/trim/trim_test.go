@@ -0,0 +1,102 @@
+package trim
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+func TestTrimPackages(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := &metadata.Function{
+		Name:      "F",
+		File:      filepath.Join(wd, "a.go"),
+		StartLine: 10,
+		EndLine:   20,
+		Statements: []*metadata.Statement{
+			{StartLine: 11, EndLine: 11, Reached: 1},
+			{StartLine: 13, EndLine: 14, Reached: 1},
+		},
+		Branches: []*metadata.Branch{
+			// 判定点与分支都不在新代码行
+			{Kind: "if", Line: 11, Outcomes: []*metadata.Outcome{{Label: "then", Line: 11, Count: 1}, {Label: "else", Line: 11}}},
+			// 只有分支在新代码行
+			{Kind: "if", Line: 12, Outcomes: []*metadata.Outcome{{Label: "then", Line: 14, Count: 1}, {Label: "else", Line: 16}}},
+			// 判定点在新代码行
+			{Kind: "switch", Line: 17, Outcomes: []*metadata.Outcome{{Label: "case", Line: 18}, {Label: "default", Line: 19, Count: 2}}},
+		},
+		Blocks: []*metadata.Block{
+			{StartLine: 11, StartCol: 2, EndLine: 12, EndCol: 5, NumStmt: 1, Count: 1},
+			// 结束于新代码行行首，不包含该行
+			{StartLine: 12, StartCol: 5, EndLine: 14, EndCol: 1, NumStmt: 1, Count: 1},
+			{StartLine: 13, StartCol: 2, EndLine: 15, EndCol: 1, NumStmt: 1, Count: 1, Tests: []string{"p.TestA"}},
+			{StartLine: 16, StartCol: 2, EndLine: 17, EndCol: 3, NumStmt: 1},
+		},
+	}
+	in := utils.Packages{{Name: "p", Mode: metadata.ModeCount, Functions: []*metadata.Function{fn}}}
+	before, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := metadata.ReservedRules{"a.go": &metadata.Rule{
+		StartLine: 14,
+		EndLine:   17,
+		LinesSet:  map[int]struct{}{14: {}, 17: {}},
+	}}
+	out, err := TrimPackages(in, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || len(out[0].Functions) != 1 {
+		t.Fatalf("TrimPackages() = %d packages, want 1 package with 1 function", len(out))
+	}
+	got := out[0].Functions[0]
+
+	if len(got.Statements) != 1 || got.Statements[0].StartLine != 13 {
+		t.Errorf("Statements = %+v, want the statement at line 13", got.Statements)
+	}
+	var branchLines []int
+	for _, b := range got.Branches {
+		branchLines = append(branchLines, b.Line)
+	}
+	if want := []int{12, 17}; !reflect.DeepEqual(branchLines, want) {
+		t.Errorf("branch lines = %v, want %v", branchLines, want)
+	}
+	var blockLines []int
+	for _, b := range got.Blocks {
+		blockLines = append(blockLines, b.StartLine)
+	}
+	if want := []int{13, 16}; !reflect.DeepEqual(blockLines, want) {
+		t.Errorf("block start lines = %v, want %v", blockLines, want)
+	}
+	if want := map[int]struct{}{14: {}}; !reflect.DeepEqual(got.NewLineSet, want) {
+		t.Errorf("NewLineSet = %v, want %v", got.NewLineSet, want)
+	}
+
+	// 修改裁剪结果不影响输入
+	for _, b := range got.Branches {
+		for _, o := range b.Outcomes {
+			o.Count += 10
+		}
+	}
+	for _, b := range got.Blocks {
+		b.Count += 10
+		b.AddTest("p.TestB")
+	}
+	after, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("input was mutated:\nbefore: %s\nafter:  %s", before, after)
+	}
+}
@@ -1,7 +1,12 @@
 package utils
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -9,10 +14,12 @@ import (
 )
 
 const (
-	Separator  = string(filepath.Separator)
-	TimeFormat = "2006_01_02_15_04_05"
-	FullHTML   = "full.html"
-	DiffHTML   = "diff.html"
+	Separator   = string(filepath.Separator)
+	TimeFormat  = "2006_01_02_15_04_05"
+	FullHTML    = "full.html"
+	DiffHTML    = "diff.html"
+	HistoryHTML = "history.html"
+	CompareHTML = "compare.html"
 )
 
 // CreateFile 创建文件
@@ -56,3 +63,36 @@ func FixPathSeparator(source string) string {
 	}
 	return source
 }
+
+// HashContent 计算文件内容的 sha256 摘要(十六进制)
+func HashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Compress 使用 gzip 压缩数据
+func Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("fail to compress data. err: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("fail to compress data. err: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress 解压 gzip 数据
+func Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("fail to decompress data. err: %v", err)
+	}
+	defer r.Close()
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("fail to decompress data. err: %v", err)
+	}
+	return out, nil
+}
@@ -6,14 +6,32 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamber92/go-cover/internal/errs"
 )
 
+// RunGit 在指定目录下执行 git 命令并返回标准输出。dir 为空时使用当前目录。
+func RunGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		gitErr := &errs.GitError{Args: args, Err: err}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			gitErr.Output = string(exitErr.Stderr)
+		}
+		return nil, gitErr
+	}
+	return output, nil
+}
+
 // GetCurrentBranch 获取当前分支名称
 func GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
-	output, err := cmd.CombinedOutput()
+	output, err := RunGit("", "branch", "--show-current")
 	if err != nil {
-		return "", fmt.Errorf("failed to get current branch-name. err: %v", err)
+		return "", err
 	}
 	br := bufio.NewReader(bytes.NewBuffer(output))
 	for {
@@ -25,3 +43,18 @@ func GetCurrentBranch() (string, error) {
 	}
 	return "", fmt.Errorf("current branch-name is empty. info: %s\n", output)
 }
+
+// GetHeadCommit 获取当前工作区所在的提交点 hash-id
+func GetHeadCommit() (string, error) {
+	output, err := RunGit("", "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ReadFileAtCommit 读取文件在指定提交点的内容
+func ReadFileAtCommit(commit, path string) ([]byte, error) {
+	dir, file := filepath.Split(path)
+	return RunGit(dir, "show", fmt.Sprintf("%s:./%s", commit, file))
+}
@@ -1,11 +1,12 @@
 package utils
 
 import (
-	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"sort"
 
+	"github.com/lamber92/go-cover/internal/errs"
 	"github.com/lamber92/go-cover/internal/metadata"
 )
 
@@ -13,17 +14,31 @@ import (
 type Packages []*metadata.Package
 
 // AppendPackage 方法可用于将包覆盖率结果合并到集合中
-func (ps *Packages) AppendPackage(p *metadata.Package) {
+func (ps *Packages) AppendPackage(p *metadata.Package) error {
 	i := sort.Search(len(*ps), func(i int) bool {
 		return (*ps)[i].Name >= p.Name
 	})
 	if i < len(*ps) && (*ps)[i].Name == p.Name {
-		(*ps)[i].Accumulate(p)
-	} else {
-		head := (*ps)[:i]
-		tail := append([]*metadata.Package{p}, (*ps)[i:]...)
-		*ps = append(head, tail...)
+		return (*ps)[i].Accumulate(p)
 	}
+	// 不同包的覆盖率模式也需要兼容，避免同一份数据中混有 set 与 count 的计数
+	if _, err := metadata.MergeMode(p.Name, ps.Mode(), p.Mode); err != nil {
+		return err
+	}
+	head := (*ps)[:i]
+	tail := append([]*metadata.Package{p}, (*ps)[i:]...)
+	*ps = append(head, tail...)
+	return nil
+}
+
+// Mode 返回数据的覆盖率模式，没有记录时返回空
+func (ps Packages) Mode() string {
+	for _, p := range ps {
+		if p.Mode != "" {
+			return p.Mode
+		}
+	}
+	return ""
 }
 
 // ReadPackages 获取文件名列表并将其内容解析为 Packages 对象
@@ -47,16 +62,16 @@ func ReadPackages(filenames []string) (ps Packages, err error) {
 
 	// 打开文件
 	var files []*os.File
-	for _, f := range filenames {
+	for _, f := range unique {
 		if f == "-" {
 			files = append(files, os.Stdin)
 		} else {
 			file, err := os.Open(f)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("fail to open coverage json. path: %s, err: %w", f, err)
 			}
 			defer file.Close()
-			files = append(files, os.Stdin)
+			files = append(files, file)
 		}
 	}
 
@@ -64,16 +79,32 @@ func ReadPackages(filenames []string) (ps Packages, err error) {
 	for _, file := range files {
 		data, err := ioutil.ReadAll(file)
 		if err != nil {
-			return nil, err
+			return nil, &errs.ParseError{File: file.Name(), Err: err}
 		}
-		result := &struct{ Packages []*metadata.Package }{}
-		err = json.Unmarshal(data, result)
+		packages, err := UnmarshalJson(data)
 		if err != nil {
-			return nil, err
+			return nil, &errs.ParseError{File: file.Name(), Err: err}
 		}
-		for _, p := range result.Packages {
-			ps.AppendPackage(p)
+		for _, p := range packages {
+			if err = ps.AppendPackage(p); err != nil {
+				return nil, err
+			}
 		}
 	}
 	return ps, nil
 }
+
+// Subtract 从集合中同名包的执行次数中减去 old 的执行次数，只存在于一方的包保持不变
+func (ps Packages) Subtract(old Packages) error {
+	for _, p := range old {
+		i := sort.Search(len(ps), func(i int) bool {
+			return ps[i].Name >= p.Name
+		})
+		if i < len(ps) && ps[i].Name == p.Name {
+			if err := ps[i].Subtract(p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
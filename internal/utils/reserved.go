@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/lamber92/go-cover/internal/errs"
 	"github.com/lamber92/go-cover/internal/metadata"
 )
 
@@ -20,7 +21,7 @@ func LoadReservedInfo(path string) (results *metadata.ReservedInfo, err error) {
 	//
 	file, err := os.OpenFile(path, os.O_RDONLY, 0666)
 	if err != nil {
-		err = fmt.Errorf("fail to open diff-file. path: %s, err: %v", path, err)
+		err = fmt.Errorf("fail to open diff-file. path: %s, err: %w", path, err)
 		return
 	}
 	defer file.Close()
@@ -39,7 +40,7 @@ func LoadReservedInfo(path string) (results *metadata.ReservedInfo, err error) {
 			if err == io.EOF {
 				break
 			}
-			return nil, err
+			return nil, &errs.ParseError{File: path, Err: err}
 		}
 
 		data := string(buff)
@@ -47,7 +48,7 @@ func LoadReservedInfo(path string) (results *metadata.ReservedInfo, err error) {
 		// 第一行是分支信息
 		if firstLine {
 			if results.Branches, err = metadata.ParseBranchesInfo(data); err != nil {
-				return nil, err
+				return nil, &errs.ParseError{File: path, Err: err}
 			}
 			firstLine = false
 			continue
@@ -71,6 +72,10 @@ func LoadReservedInfo(path string) (results *metadata.ReservedInfo, err error) {
 			newLines    = StringsToInts(lines)
 			newLinesSet = make(map[int]struct{})
 		)
+		if len(newLines) == 0 {
+			log.Printf("invalid lines: %+v\n", lines)
+			continue
+		}
 		for _, v := range newLines {
 			newLinesSet[v] = struct{}{}
 		}
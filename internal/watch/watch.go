@@ -0,0 +1,173 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultInterval 是检查 Go 文件变更的默认间隔
+const DefaultInterval = time.Second
+
+// Watcher 轮询包模式匹配的目录中的 Go 文件，找出发生变更的包
+type Watcher struct {
+	patterns []pattern
+	stats    map[string]fileStat
+}
+
+// pattern 是本地包模式，如 ./...、./internal/...、./cmd
+type pattern struct {
+	dir       string // 清理后的目录
+	recursive bool   // 是否以 /... 结尾
+}
+
+// fileStat 记录文件的修改时间与大小，用于判断文件是否变更
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// New 创建 Watcher。只支持以 . 开头的本地包模式
+func New(patterns []string) (*Watcher, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	w := &Watcher{}
+	for _, p := range patterns {
+		if p != "." && !strings.HasPrefix(p, "./") && !strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("only local package patterns are supported. [%s]", p)
+		}
+		recursive := strings.HasSuffix(p, "/...")
+		w.patterns = append(w.patterns, pattern{
+			dir:       filepath.Clean(strings.TrimSuffix(p, "/...")),
+			recursive: recursive,
+		})
+	}
+	return w, nil
+}
+
+// Changed 扫描文件并返回自上次扫描后有 Go 文件新增、修改或删除的包目录 pkgs，
+// 以及删除了最后一个 Go 文件、不再是包的目录 removed，均以 ./dir 形式的包模式返回并排序。
+// 首次扫描返回所有包目录。
+func (w *Watcher) Changed() (pkgs, removed []string, err error) {
+	stats := make(map[string]fileStat)
+	for _, p := range w.patterns {
+		if err := scan(p, stats); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	dirs := make(map[string]struct{})
+	for file, stat := range stats {
+		if old, ok := w.stats[file]; !ok || old != stat {
+			dirs[filepath.Dir(file)] = struct{}{}
+		}
+	}
+	for file := range w.stats {
+		if _, ok := stats[file]; !ok {
+			dirs[filepath.Dir(file)] = struct{}{}
+		}
+	}
+	w.stats = stats
+
+	for dir := range dirs {
+		// 删除了最后一个 Go 文件的目录不再是包
+		if hasGoFiles(dir, stats) {
+			pkgs = append(pkgs, packagePattern(dir))
+		} else {
+			removed = append(removed, packagePattern(dir))
+		}
+	}
+	sort.Strings(pkgs)
+	sort.Strings(removed)
+	return pkgs, removed, nil
+}
+
+// Run 按间隔检查文件，首次以及每次有包变更或删除时调用 fn，直到 stop 被关闭。pkgs 与 removed 的含义见 Changed
+func (w *Watcher) Run(interval time.Duration, stop <-chan struct{}, fn func(pkgs, removed []string)) error {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		pkgs, removed, err := w.Changed()
+		if err != nil {
+			return err
+		}
+		if len(pkgs) > 0 || len(removed) > 0 {
+			fn(pkgs, removed)
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan 记录模式匹配的目录中所有 Go 文件的状态。跳过 vendor、testdata 以及以 . 或 _ 开头的目录
+func scan(p pattern, stats map[string]fileStat) error {
+	if !p.recursive {
+		infos, err := readDir(p.dir)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if !info.IsDir() && isGoFile(info.Name()) {
+				stats[filepath.Join(p.dir, info.Name())] = fileStat{modTime: info.ModTime(), size: info.Size()}
+			}
+		}
+		return nil
+	}
+	return filepath.Walk(p.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != p.dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isGoFile(info.Name()) {
+			stats[path] = fileStat{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+}
+
+func readDir(dir string) ([]os.FileInfo, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdir(-1)
+}
+
+func isGoFile(name string) bool {
+	return strings.HasSuffix(name, ".go") && !strings.HasPrefix(name, ".") && !strings.HasPrefix(name, "_")
+}
+
+func hasGoFiles(dir string, stats map[string]fileStat) bool {
+	for file := range stats {
+		if filepath.Dir(file) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// packagePattern 将目录转换为 go 命令可识别的本地包模式
+func packagePattern(dir string) string {
+	dir = filepath.ToSlash(dir)
+	if dir == "." || strings.HasPrefix(dir, "../") {
+		return dir
+	}
+	return "./" + dir
+}
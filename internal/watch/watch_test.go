@@ -0,0 +1,71 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := filepath.Rel(wd, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "a", "a.go"), "package a")
+	writeFile(t, filepath.Join(dir, "b", "b.go"), "package b")
+	writeFile(t, filepath.Join(dir, "b", "testdata", "x.go"), "package x")
+	writeFile(t, filepath.Join(dir, "c", "README"), "c")
+
+	w, err := New([]string{filepath.ToSlash(dir) + "/..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := func(name string) string { return packagePattern(filepath.Join(dir, name)) }
+
+	got, removed, err := w.Changed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{pkg("a"), pkg("b")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first Changed() = %v, want %v", got, want)
+	}
+	if len(removed) != 0 {
+		t.Errorf("first Changed() removed = %v, want none", removed)
+	}
+	if got, removed, _ = w.Changed(); len(got) != 0 || len(removed) != 0 {
+		t.Errorf("Changed() without changes = %v, %v, want none", got, removed)
+	}
+
+	writeFile(t, filepath.Join(dir, "b", "b_test.go"), "package b")
+	if err = os.Remove(filepath.Join(dir, "a", "a.go")); err != nil {
+		t.Fatal(err)
+	}
+	if got, removed, _ = w.Changed(); !reflect.DeepEqual(got, []string{pkg("b")}) {
+		t.Errorf("Changed() after adding a test = %v, want %v", got, []string{pkg("b")})
+	}
+	if want := []string{pkg("a")}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("Changed() removed after deleting a.go = %v, want %v", removed, want)
+	}
+}
+
+func TestNewRejectsImportPaths(t *testing.T) {
+	if _, err := New([]string{"github.com/x/y/..."}); err == nil {
+		t.Error("New() with an import path pattern succeeded, want an error")
+	}
+}
@@ -0,0 +1,24 @@
+package gocover_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/lamber92/go-cover/pkg/gocover"
+)
+
+func ExampleConvert() {
+	ps, _, err := gocover.Convert("testdata/calc.out", gocover.ConvertOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range ps {
+		for _, f := range p.Functions {
+			fmt.Println(f.Name, len(f.Statements))
+		}
+	}
+	// Output:
+	// Abs 3
+	// Sign 4
+	// Both 5
+}
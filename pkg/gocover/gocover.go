@@ -0,0 +1,244 @@
+// Package gocover 是 go-cover 对外提供的稳定 API。
+//
+// 它将 Go-Coverage-Profile 的转换、分支差异计算、增量裁剪、覆盖率合并以及 HTML 报告渲染
+// 封装为独立的函数，所有选项均通过参数结构体传入，不依赖命令行参数。
+package gocover
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/jinzhu/copier"
+	"github.com/lamber92/go-cover/internal/convert"
+	"github.com/lamber92/go-cover/internal/diff"
+	"github.com/lamber92/go-cover/internal/errs"
+	"github.com/lamber92/go-cover/internal/metadata"
+	"github.com/lamber92/go-cover/internal/profile"
+	"github.com/lamber92/go-cover/internal/report"
+	"github.com/lamber92/go-cover/internal/report/types"
+	"github.com/lamber92/go-cover/internal/trim"
+	"github.com/lamber92/go-cover/internal/utils"
+)
+
+type (
+	// Packages 是一组包的覆盖率数据，按包名排序。
+	Packages = utils.Packages
+	// Package 是单个包的覆盖率数据。
+	Package = metadata.Package
+	// Function 是单个函数的覆盖率数据。
+	Function = metadata.Function
+	// Statement 是单个语句的覆盖率数据。
+	Statement = metadata.Statement
+	// Block 是 profile 中记录的原始覆盖块。
+	Block = metadata.Block
+	// Metric 是覆盖率的统计口径。
+	Metric = metadata.Metric
+	// Coverage 是某一统计口径下的覆盖情况。
+	Coverage = metadata.Coverage
+	// Source 是转换时记录的源文件信息。
+	Source = metadata.Source
+	// BranchesInfo 是增量覆盖率对应的分支及提交点信息。
+	BranchesInfo = metadata.BranchesInfo
+	// Rule 是单个文件需要保留的行号规则。
+	Rule = metadata.Rule
+	// ReservedRules 是以文件路径为键的保留规则。
+	ReservedRules = metadata.ReservedRules
+	// SkippedFile 是在“继续执行”模式下被跳过的文件。
+	SkippedFile = errs.SkippedFile
+	// SortMethod 是报告中函数列表的排序方式。
+	SortMethod = types.SortMethod
+)
+
+// 支持的函数排序方式
+const (
+	SortByCoverage   = types.SortByCoverage
+	SortByUncovered  = types.SortByUncovered
+	SortByCRAP       = types.SortByCRAP
+	SortByComplexity = types.SortByComplexity
+	SortByName       = types.SortByName
+)
+
+// 支持的覆盖率统计口径
+const (
+	MetricStatement = metadata.MetricStatement
+	MetricLine      = metadata.MetricLine
+	MetricBlock     = metadata.MetricBlock
+	MetricBranch    = metadata.MetricBranch
+)
+
+type (
+	// MissingSourceError 表示找不到(或无法读取)源文件。
+	MissingSourceError = errs.MissingSourceError
+	// ParseError 表示解析文件失败。
+	ParseError = errs.ParseError
+	// GitError 表示执行 git 命令失败。
+	GitError = errs.GitError
+	// MergeError 表示合并覆盖率数据时两份数据不匹配。
+	MergeError = errs.MergeError
+)
+
+// ConvertOptions 是转换 Go-Coverage-Profile 的选项。
+type ConvertOptions struct {
+	// EmbedSource 是否将源文件内容(压缩后)嵌入到转换结果中，便于脱离源码目录渲染报告
+	EmbedSource bool
+	// KeepGoing 是否跳过找不到或无法解析的源文件继续转换
+	KeepGoing bool
+	// Workers 是并发转换的协程数。小于等于 0 时使用 CPU 核数
+	Workers int
+}
+
+// Convert 加载并转换 Go-Coverage-Profile 文件。
+// 开启 KeepGoing 时，被跳过的文件通过 skipped 返回。
+func Convert(profile string, opts ConvertOptions) (ps Packages, skipped []*SkippedFile, err error) {
+	return convert.Do(profile, &convert.Param{
+		EmbedSource: opts.EmbedSource,
+		KeepGoing:   opts.KeepGoing,
+		Workers:     opts.Workers,
+	})
+}
+
+// DiffOptions 是计算分支差异的选项。
+type DiffOptions struct {
+	// CurrentBranch 是当前被测分支。为空时调用 git 获取
+	CurrentBranch string
+	// TargetBranch 是被对比的分支。为空时使用 master 分支
+	TargetBranch string
+	// StartHashID、EndHashID 限定当前分支需要保留的提交点区间。为空时采集所有提交点
+	StartHashID string
+	EndHashID   string
+}
+
+// DiffResult 是分支差异的计算结果。
+type DiffResult struct {
+	Branches *BranchesInfo
+	Rules    ReservedRules
+}
+
+// Diff 调用 git 计算当前分支相对于被对比分支新增的代码行。
+func Diff(opts DiffOptions) (*DiffResult, error) {
+	targetBranch := opts.TargetBranch
+	if len(targetBranch) == 0 {
+		targetBranch = diff.DefaultTargetBranch
+	}
+	var hashIdsRange []string
+	if len(opts.StartHashID) > 0 || len(opts.EndHashID) > 0 {
+		hashIdsRange = []string{opts.StartHashID, opts.EndHashID}
+	}
+	d, err := diff.Do(opts.CurrentBranch, targetBranch, hashIdsRange)
+	if err != nil {
+		return nil, err
+	}
+	return &DiffResult{
+		Branches: &BranchesInfo{
+			TargetBranchName:  d.TargetBranch,
+			CurrentBranchName: d.CurrentBranch,
+			StartHashID:       d.CommitHashIdRange[0],
+			EndHashID:         d.CommitHashIdRange[1],
+		},
+		Rules: d.ConvToReservedRules(),
+	}, nil
+}
+
+// LoadDiff 加载由 diff 命令输出的差异信息文件。
+func LoadDiff(path string) (*DiffResult, error) {
+	info, err := utils.LoadReservedInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	return &DiffResult{Branches: info.Branches, Rules: info.Rules}, nil
+}
+
+// Trim 按保留规则裁剪出增量覆盖率数据，不会修改传入的数据。
+func Trim(ps Packages, rules ReservedRules) (Packages, error) {
+	return trim.TrimPackages(ps, rules)
+}
+
+// Merge 合并多份基于同一份代码的覆盖率数据，不会修改传入的数据。
+// 同名包的函数或语句不匹配时返回 *MergeError。
+func Merge(sets ...Packages) (Packages, error) {
+	out := make(Packages, 0)
+	for _, ps := range sets {
+		copied := make(Packages, 0, len(ps))
+		if err := copier.CopyWithOption(&copied, &ps, copier.Option{DeepCopy: true}); err != nil {
+			return nil, fmt.Errorf("failed to copy packages. err: %w", err)
+		}
+		for _, p := range copied {
+			if err := out.AppendPackage(p); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// ReadJSON 读取 go-cover 的 json 格式覆盖率数据。
+func ReadJSON(r io.Reader) (Packages, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return utils.UnmarshalJson(data)
+}
+
+// WriteJSON 以 go-cover 的 json 格式输出覆盖率数据。
+func WriteJSON(w io.Writer, ps Packages) error {
+	return utils.MarshalJson(w, ps)
+}
+
+// RenderOptions 是渲染 HTML 报告的选项。
+type RenderOptions struct {
+	// Diff 为 true 时输出增量报告(高亮新代码行)，否则输出全量报告
+	Diff bool
+	// CSS 是自定义样式表的路径。为空时使用默认样式
+	CSS string
+	// Branches 是报告头部展示的分支信息
+	Branches *BranchesInfo
+	// GitFallback 源文件已变更时，是否从转换时记录的提交点取回原始内容
+	GitFallback bool
+	// KeepGoing 源文件缺失时是否继续渲染其余内容，并在报告中列出被跳过的文件
+	KeepGoing bool
+	// Skipped 是转换阶段被跳过的文件，会在报告中列出
+	Skipped []*SkippedFile
+	// Sort 是函数列表的排序方式，缺省时按覆盖率从高到低排列
+	Sort SortMethod
+}
+
+// RenderHTML 将 HTML 覆盖率报告输出到 w。
+func RenderHTML(w io.Writer, ps Packages, opts RenderOptions) error {
+	return report.WriteHTML(w, &report.GenerateHTMLParam{
+		Packages:     ps,
+		CSS:          opts.CSS,
+		BranchesInfo: opts.Branches,
+		GitFallback:  opts.GitFallback,
+		KeepGoing:    opts.KeepGoing,
+		Skipped:      opts.Skipped,
+		Sort:         opts.Sort,
+	}, opts.Diff)
+}
+
+// RenderText 将纯文本覆盖率报告输出到 w，函数按 sortBy 排序。
+func RenderText(w io.Writer, ps Packages, sortBy SortMethod) error {
+	return report.WriteText(w, ps, sortBy)
+}
+
+// RenderMarkdown 将 Markdown 覆盖率摘要输出到 w，函数明细按 sortBy 排序。
+func RenderMarkdown(w io.Writer, ps Packages, sortBy SortMethod) error {
+	return report.WriteMarkdown(w, ps, sortBy)
+}
+
+// RenderSonar 将 SonarQube 通用覆盖率 XML 输出到 w，文件路径为相对 root 的路径。
+func RenderSonar(w io.Writer, ps Packages, root string) error {
+	return report.WriteSonar(w, ps, root)
+}
+
+// RenderCobertura 将 Cobertura XML 输出到 w，文件路径为相对 root 的路径。
+func RenderCobertura(w io.Writer, ps Packages, root string) error {
+	return report.WriteCobertura(w, ps, root)
+}
+
+// RenderProfile 将覆盖率数据写回 go test -coverprofile 的文本格式。
+// mode 为空时使用数据记录的覆盖率模式。
+func RenderProfile(w io.Writer, ps Packages, mode string) error {
+	return profile.Write(w, ps, mode)
+}
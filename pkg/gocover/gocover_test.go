@@ -0,0 +1,185 @@
+package gocover
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testProfile = "testdata/calc.out"
+
+func reachedStatements(ps Packages) (reached, total int64) {
+	for _, p := range ps {
+		for _, f := range p.Functions {
+			for _, s := range f.Statements {
+				total++
+				reached += s.Reached
+			}
+		}
+	}
+	return
+}
+
+func TestConvert(t *testing.T) {
+	ps, skipped, err := Convert(testProfile, ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("Convert() skipped = %v, want none", skipped)
+	}
+	if len(ps) != 1 {
+		t.Fatalf("Convert() = %d packages, want 1", len(ps))
+	}
+	if got := len(ps[0].Functions); got != 3 {
+		t.Errorf("Convert() = %d functions, want 3", got)
+	}
+	if reached, total := reachedStatements(ps); reached != 4 || total != 12 {
+		t.Errorf("Convert() reached %d/%d statements, want 4/12", reached, total)
+	}
+}
+
+func TestConvertKeepGoing(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "missing.out")
+	content := "mode: set\n./testdata/calc/calc.go:5.2,5.11 1 1\n./testdata/calc/gone.go:5.2,5.11 1 1\n"
+	if err := ioutil.WriteFile(profile, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := Convert(profile, ConvertOptions{})
+	var missingErr *MissingSourceError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("Convert() err = %v, want MissingSourceError", err)
+	}
+
+	ps, skipped, err := Convert(profile, ConvertOptions{KeepGoing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || len(skipped) != 1 {
+		t.Errorf("Convert() = %d packages and %d skipped files, want 1 and 1", len(ps), len(skipped))
+	}
+}
+
+func TestTrim(t *testing.T) {
+	ps, _, err := Convert(testProfile, ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := ReservedRules{
+		"testdata/calc/calc.go": &Rule{StartLine: 5, EndLine: 6, LinesSet: map[int]struct{}{5: {}, 6: {}}},
+	}
+	out, err := Trim(ps, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || len(out[0].Functions) != 1 || out[0].Functions[0].Name != "Abs" {
+		t.Fatalf("Trim() kept unexpected functions: %+v", out)
+	}
+	if got := len(ps[0].Functions); got != 3 {
+		t.Errorf("Trim() modified the input: %d functions, want 3", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	ps, _, err := Convert(testProfile, ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// testdata 是 set 模式，合并结果是两者之一执行过即记为 1
+	merged, err := Merge(ps, ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reached, _ := reachedStatements(merged); reached != 4 {
+		t.Errorf("Merge() of set data reached = %d, want 4", reached)
+	}
+
+	// count 模式的执行次数相加
+	for _, p := range ps {
+		p.Mode = "count"
+	}
+	if merged, err = Merge(ps, ps); err != nil {
+		t.Fatal(err)
+	}
+	if reached, _ := reachedStatements(merged); reached != 8 {
+		t.Errorf("Merge() reached = %d, want 8", reached)
+	}
+	if reached, _ := reachedStatements(ps); reached != 4 {
+		t.Errorf("Merge() modified the input: reached = %d, want 4", reached)
+	}
+
+	other := Packages{&Package{Name: ps[0].Name}}
+	_, err = Merge(ps, other)
+	var mergeErr *MergeError
+	if !errors.As(err, &mergeErr) {
+		t.Errorf("Merge() err = %v, want MergeError", err)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	ps, _, err := Convert(testProfile, ConvertOptions{EmbedSource: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = WriteJSON(&buf, ps); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(got[0].Sources) != 1 || len(got[0].Sources[0].Content) == 0 {
+		t.Errorf("ReadJSON() lost data: %+v", got)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	ps, _, err := Convert(testProfile, ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = RenderHTML(&buf, ps, RenderOptions{Branches: &BranchesInfo{CurrentBranchName: "feature"}}); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{"func Abs", "About feature", `class="miss"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("RenderHTML() output does not contain %q", want)
+		}
+	}
+}
+
+// TestRenderHTMLDiff 按固定的差异规则裁剪后输出增量报告，不依赖 git 状态
+func TestRenderHTMLDiff(t *testing.T) {
+	ps, _, err := Convert(testProfile, ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := ReservedRules{
+		"testdata/calc/calc.go": &Rule{StartLine: 5, EndLine: 6, LinesSet: map[int]struct{}{5: {}, 6: {}}},
+	}
+	diffPackages, err := Trim(ps, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	branches := &BranchesInfo{TargetBranchName: "main", CurrentBranchName: "feature"}
+	if err = RenderHTML(&buf, diffPackages, RenderOptions{Diff: true, Branches: branches}); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{"func Abs", "main", "feature"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("RenderHTML() output does not contain %q", want)
+		}
+	}
+	if strings.Contains(html, "func Sign") {
+		t.Error("RenderHTML() diff output contains the untouched function Sign")
+	}
+}
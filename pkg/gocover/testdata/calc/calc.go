@@ -0,0 +1,30 @@
+package calc
+
+// Abs returns absolute value.
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Sign returns the sign.
+func Sign(x int) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+func Both(a, b bool) bool {
+	if a && b {
+		return true
+	} else if a {
+		return false
+	} else {
+		return b
+	}
+}
@@ -0,0 +1,11 @@
+// Package livecover 为以 -cover 构建的程序(Go 1.20+)提供采集覆盖率的 HTTP 接口，
+// 程序无需退出即可取得当前的覆盖率。
+//
+// 将 Handler 挂载到程序已有的 HTTP 服务上：
+//
+//	http.Handle("/debug/cover", livecover.Handler())
+//
+// GET 返回当前覆盖率的快照：包含 runtime/coverage 写出的 covmeta、covcounters 文件的 tar.gz，
+// 可用 go tool covdata 转换为 profile；POST 将计数清零(程序需以 -covermode=atomic 构建)。
+// go-cover collect --api runtime 会下载快照并生成报告。
+package livecover
@@ -0,0 +1,89 @@
+//go:build go1.20
+// +build go1.20
+
+package livecover
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/coverage"
+)
+
+// Handler 返回采集覆盖率的 http.Handler，GET 返回快照，POST 清零计数
+func Handler() http.Handler {
+	return http.HandlerFunc(serveHTTP)
+}
+
+func serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		dir, err := os.MkdirTemp("", "livecover-*")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer os.RemoveAll(dir)
+		if err = Snapshot(dir); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		if err = writeArchive(w, dir); err != nil {
+			// 响应已经开始写入，只能中断连接让客户端感知到错误
+			panic(http.ErrAbortHandler)
+		}
+	case http.MethodPost:
+		if err := coverage.ClearCounters(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Snapshot 将当前的覆盖率元数据与计数写入目录 dir
+func Snapshot(dir string) error {
+	if err := coverage.WriteMetaDir(dir); err != nil {
+		return fmt.Errorf("failed to write coverage meta-data. err: %w", err)
+	}
+	if err := coverage.WriteCountersDir(dir); err != nil {
+		return fmt.Errorf("failed to write coverage counters. err: %w", err)
+	}
+	return nil
+}
+
+// writeArchive 将目录下的文件打包为 tar.gz
+func writeArchive(w io.Writer, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err = tw.WriteHeader(&tar.Header{Name: e.Name(), Mode: 0644, Size: int64(len(data))}); err != nil {
+			return err
+		}
+		if _, err = tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
@@ -0,0 +1,109 @@
+//go:build go1.20
+// +build go1.20
+
+package livecover
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readArchive 读取 tar.gz 中的文件，返回文件名到内容的映射
+func readArchive(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[h.Name] = string(data)
+	}
+	return files
+}
+
+func TestWriteArchive(t *testing.T) {
+	dir := t.TempDir()
+	want := map[string]string{
+		"covmeta.0123abcd":              "meta-data",
+		"covcounters.0123abcd.1234.567": "counters",
+	}
+	for name, content := range want {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 子目录不会被打包
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := readArchive(t, &buf); !reflect.DeepEqual(got, want) {
+		t.Errorf("writeArchive() = %v, want %v", got, want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	resp, err := http.Head(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("HEAD status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+
+	resp, err = http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	// 只有以 -cover 构建的测试程序才带有 runtime/coverage 可用的元数据，否则应返回错误
+	if err = Snapshot(t.TempDir()); err != nil {
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d when %v", resp.StatusCode, http.StatusInternalServerError, err)
+		}
+		t.Skipf("binary not built with -cover: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	var meta, counters bool
+	for name := range readArchive(t, resp.Body) {
+		meta = meta || strings.HasPrefix(name, "covmeta.")
+		counters = counters || strings.HasPrefix(name, "covcounters.")
+	}
+	if !meta || !counters {
+		t.Errorf("snapshot has meta-data %v, counters %v, want both", meta, counters)
+	}
+}